@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+// defaultConfigFile is the project config file which is picked up automatically
+const defaultConfigFile = ".yaml-readme.yaml"
+
+// config is the project config which declares the render jobs, for example:
+//
+//	jobs:
+//	- name: tools
+//	  pattern: items/*.yaml
+//	  template: README.tpl
+//	  output: README.md
+type config struct {
+	Jobs []job `yaml:"jobs"`
+}
+
+// job declares the settings of one rendering, the empty fields follow the template header and the defaults
+type job struct {
+	Name       string            `yaml:"name"`
+	Pattern    string            `yaml:"pattern"`
+	Template   string            `yaml:"template"`
+	Partials   string            `yaml:"partials"`
+	Layout     string            `yaml:"layout"`
+	Engine     string            `yaml:"engine"`
+	Delims     string            `yaml:"delims"`
+	Output     string            `yaml:"output"`
+	Section    string            `yaml:"section"`
+	SortBy     string            `yaml:"sort-by"`
+	Collation  string            `yaml:"collation"`
+	GroupBy    string            `yaml:"group-by"`
+	GroupOrder string            `yaml:"group-order"`
+	Filter     string            `yaml:"filter"`
+	Schema     string            `yaml:"schema"`
+	Header     *bool             `yaml:"header"`
+	Strict     *bool             `yaml:"strict"`
+	GitInfo    *bool             `yaml:"git-info"`
+	Fallback   string            `yaml:"fallback"`
+	Variables  map[string]string `yaml:"variables"`
+	VarsFile   string            `yaml:"vars-file"`
+	Env        []string          `yaml:"env"`
+}
+
+// loadConfig loads the config file, it returns nil if the file does not exist and it's not required
+func loadConfig(configFile string, required bool) (cfg *config, err error) {
+	var data []byte
+	if data, err = os.ReadFile(configFile); err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			err = nil
+		}
+		return
+	}
+
+	cfg = &config{}
+	if err = yaml.UnmarshalStrict(data, cfg); err != nil {
+		err = fmt.Errorf("failed to parse config file %q, error: %v", configFile, err)
+		return
+	}
+
+	names := make(map[string]bool, len(cfg.Jobs))
+	for i, j := range cfg.Jobs {
+		if j.Name == "" {
+			err = fmt.Errorf("the name of job %d is required in config file %q", i, configFile)
+		} else if names[j.Name] {
+			err = fmt.Errorf("duplicated job %q in config file %q", j.Name, configFile)
+		}
+		if err != nil {
+			return
+		}
+		names[j.Name] = true
+	}
+	return
+}
+
+// getJobs returns the job with the given name, or all the jobs if the name is empty
+func (c *config) getJobs(name string) (jobs []job, err error) {
+	if name == "" {
+		jobs = c.Jobs
+		return
+	}
+
+	for _, j := range c.Jobs {
+		if j.Name == name {
+			jobs = append(jobs, j)
+			return
+		}
+	}
+	err = fmt.Errorf("cannot find job %q", name)
+	return
+}
+
+// apply sets the flags from the job, the flags which were set already are not overridden
+func (j *job) apply(flags *pflag.FlagSet) (err error) {
+	var header, strict, gitInfo string
+	if j.Header != nil {
+		header = strconv.FormatBool(*j.Header)
+	}
+	if j.Strict != nil {
+		strict = strconv.FormatBool(*j.Strict)
+	}
+	if j.GitInfo != nil {
+		gitInfo = strconv.FormatBool(*j.GitInfo)
+	}
+
+	values := [][2]string{
+		{"pattern", j.Pattern},
+		{"template", j.Template},
+		{"partials", j.Partials},
+		{"layout", j.Layout},
+		{"engine", j.Engine},
+		{"delims", j.Delims},
+		{"output", j.Output},
+		{"section", j.Section},
+		{"sort-by", j.SortBy},
+		{"collation", j.Collation},
+		{"group-by", j.GroupBy},
+		{"group-order", j.GroupOrder},
+		{"filter", j.Filter},
+		{"vars-file", j.VarsFile},
+		{"env", strings.Join(j.Env, ",")},
+		{"schema", j.Schema},
+		{"include-header", header},
+		{"strict", strict},
+		{"git-info", gitInfo},
+		{"fallback", j.Fallback},
+	}
+	for _, value := range values {
+		if value[1] != "" && !flags.Changed(value[0]) {
+			if err = flags.Set(value[0], value[1]); err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+// newJobOption resolves the option of a job, the priority from high to low is:
+// the explicit flags, the job, the template header and the defaults
+func newJobOption(j *job, explicitFlags *pflag.FlagSet, ignoredFlags ...string) (opt *option, err error) {
+	opt = &option{}
+	flags := pflag.NewFlagSet(j.Name, pflag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	opt.addFlags(flags)
+
+	if explicitFlags != nil {
+		ignored := make(map[string]bool, len(ignoredFlags))
+		for _, name := range ignoredFlags {
+			ignored[name] = true
+		}
+
+		explicitFlags.Visit(func(flag *pflag.Flag) {
+			if err != nil || ignored[flag.Name] || flags.Lookup(flag.Name) == nil {
+				return
+			}
+			err = setFlag(flags, flag)
+		})
+	}
+	if err != nil {
+		return
+	}
+
+	if err = j.apply(flags); err != nil {
+		return
+	}
+
+	var headerArgs []string
+	if headerArgs, err = readTemplateHeader(opt.templateFile); err != nil {
+		err = fmt.Errorf("failed to parse the header of template %q, error: %v", opt.templateFile, err)
+		return
+	}
+	if err = applyHeaderArgs(flags, headerArgs); err != nil {
+		err = fmt.Errorf("invalid header of template %q, error: %v", opt.templateFile, err)
+		return
+	}
+	opt.vars = j.Variables
+	return
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_loadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("not exist", func(t *testing.T) {
+		cfg, err := loadConfig(filepath.Join(dir, "fake.yaml"), false)
+		assert.Nil(t, err)
+		assert.Nil(t, cfg)
+
+		_, err = loadConfig(filepath.Join(dir, "fake.yaml"), true)
+		assert.NotNil(t, err)
+	})
+
+	tests := []struct {
+		name     string
+		content  string
+		hasError bool
+	}{{
+		name: "normal",
+		content: `jobs:
+- name: a
+  header: false
+  variables:
+    repo: yaml-readme`,
+	}, {
+		name:     "unknown field",
+		content:  "jobs:\n- name: a\n  fake: b",
+		hasError: true,
+	}, {
+		name:     "without name",
+		content:  "jobs:\n- pattern: a",
+		hasError: true,
+	}, {
+		name:     "duplicated name",
+		content:  "jobs:\n- name: a\n- name: a",
+		hasError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(dir, "config.yaml")
+			writeFile(t, configFile, tt.content)
+
+			cfg, err := loadConfig(configFile, true)
+			if tt.hasError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, "a", cfg.Jobs[0].Name)
+			assert.False(t, *cfg.Jobs[0].Header)
+			assert.Equal(t, map[string]string{"repo": "yaml-readme"}, cfg.Jobs[0].Variables)
+
+			_, err = cfg.getJobs("fake")
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestCommandWithConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a\nkind: tool")
+	writeFile(t, filepath.Join(dir, "items", "b.yaml"), "name: b\nkind: book")
+	writeFile(t, filepath.Join(dir, "names.tpl"), "#!yaml-readme --sort-by !name\n"+
+		`{{variable "title"}}:{{- range .}}{{.name}}{{end}}`)
+	writeFile(t, filepath.Join(dir, "kinds.tpl"), "{{- range $key, $val := .}}{{$key}}{{end}}")
+	writeFile(t, filepath.Join(dir, defaultConfigFile), `jobs:
+- name: names
+  pattern: items/*.yaml
+  template: names.tpl
+  output: names.md
+  header: false
+  variables:
+    title: Names
+- name: kinds
+  pattern: items/*.yaml
+  template: kinds.tpl
+  group-by: kind
+  header: false`)
+
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(dir))
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	tests := []struct {
+		name         string
+		flags        []string
+		hasError     bool
+		expectError  string
+		expectOutput string
+		expectFile   string
+		expectStderr string
+	}{{
+		name:         "run all jobs",
+		expectOutput: "booktool",
+		expectFile:   "Names:ba",
+	}, {
+		name:         "override the selected job",
+		flags:        []string{"--job", "names", "--sort-by", "name"},
+		expectOutput: "",
+		expectFile:   "Names:ab",
+	}, {
+		name:         "run the selected job",
+		flags:        []string{"--job", "kinds"},
+		expectOutput: "booktool",
+	}, {
+		name:         "override all jobs",
+		flags:        []string{"--var", "title=Items"},
+		expectOutput: "booktool",
+		expectFile:   "Items:ba",
+	}, {
+		name:     "check all jobs",
+		flags:    []string{"--check"},
+		hasError: true,
+		// the stale output is printed, and the job without output fails
+		expectError:  "the check mode requires an output file",
+		expectOutput: "--- names.md\n",
+	}, {
+		name:     "unknown job",
+		flags:    []string{"--job", "fake"},
+		hasError: true,
+	}, {
+		name:     "job without config",
+		flags:    []string{"--job", "names", "--config", "fake.yaml"},
+		hasError: true,
+	}, {
+		name:         "ignore the config without a job",
+		flags:        []string{"-t", "kinds.tpl", "-p", "items/*.yaml", "--include-header=false"},
+		expectOutput: "01",
+		expectStderr: `skip the config file ".yaml-readme.yaml" because of the explicit flags, select a job by --job to override it` + "\n",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = os.Remove("names.md")
+
+			cmd := newRootCommand()
+			buf, stderr := bytes.NewBuffer([]byte{}), bytes.NewBuffer([]byte{})
+			cmd.SetOut(buf)
+			cmd.SetErr(stderr)
+			cmd.SetArgs(tt.flags)
+
+			err := cmd.Execute()
+			if tt.hasError {
+				if assert.NotNil(t, err) && tt.expectError != "" {
+					assert.Contains(t, err.Error(), tt.expectError)
+				}
+				assert.Contains(t, buf.String(), tt.expectOutput)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectOutput, buf.String())
+			assert.Equal(t, tt.expectStderr, stderr.String())
+
+			if tt.expectFile != "" {
+				data, err := os.ReadFile("names.md")
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expectFile, string(data))
+			}
+		})
+	}
+}
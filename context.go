@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// templateContext holds the reserved fields of the template root, they are the methods of the root,
+// so the templates which range over the root directly still work
+type templateContext struct {
+	vars   map[string]interface{}
+	env    map[string]string
+	items  []map[string]interface{}
+	groups interface{}
+}
+
+// templateContexts are the contexts of the roots being rendered, the key is the pointer of a root
+var templateContexts sync.Map
+
+// listRoot is the root of the template when it's a list of items or ordered groups
+type listRoot[T any] []T
+
+// mapRoot is the root of the template when it's the groups ranged by the keys
+type mapRoot[T any] map[string]T
+
+// Vars returns the variables from the flags, the variables file and the config
+func (r listRoot[T]) Vars() map[string]interface{} { return contextOf(r).vars }
+
+// Env returns the allowed environment variables
+func (r listRoot[T]) Env() map[string]string { return contextOf(r).env }
+
+// Items returns all the items after filtering and sorting
+func (r listRoot[T]) Items() []map[string]interface{} { return contextOf(r).items }
+
+// Groups returns the groups, it's nil if the items are not grouped
+func (r listRoot[T]) Groups() interface{} { return contextOf(r).groups }
+
+// Vars returns the variables from the flags, the variables file and the config
+func (r mapRoot[T]) Vars() map[string]interface{} { return contextOf(r).vars }
+
+// Env returns the allowed environment variables
+func (r mapRoot[T]) Env() map[string]string { return contextOf(r).env }
+
+// Items returns all the items after filtering and sorting
+func (r mapRoot[T]) Items() []map[string]interface{} { return contextOf(r).items }
+
+// Groups returns the groups, it's nil if the items are not grouped
+func (r mapRoot[T]) Groups() interface{} { return contextOf(r).groups }
+
+func contextOf(root interface{}) *templateContext {
+	if ctx, ok := templateContexts.Load(reflect.ValueOf(root).Pointer()); ok {
+		return ctx.(*templateContext)
+	}
+	return &templateContext{}
+}
+
+// newTemplateRoot wraps the data with the context, the context is released after rendering
+func newTemplateRoot(data interface{}, ctx *templateContext) (root interface{}, release func()) {
+	switch val := data.(type) {
+	case []map[string]interface{}:
+		// the extra capacity makes sure the pointer of an empty root is unique
+		root = append(make(listRoot[map[string]interface{}], 0, len(val)+1), val...)
+	case []*group:
+		root = append(make(listRoot[*group], 0, len(val)+1), val...)
+	case map[string][]map[string]interface{}:
+		root = mapRoot[[]map[string]interface{}](val)
+	case map[string]interface{}:
+		root = mapRoot[interface{}](val)
+	default:
+		root, release = data, func() {}
+		return
+	}
+
+	key := reflect.ValueOf(root).Pointer()
+	templateContexts.Store(key, ctx)
+	release = func() {
+		templateContexts.Delete(key)
+	}
+	return
+}
+
+// loadVars merges the variables from low to high priority: the config, the variables file and the flags
+func (o *option) loadVars() (vars map[string]interface{}, err error) {
+	vars = make(map[string]interface{}, len(o.vars))
+	for key, val := range o.vars {
+		vars[key] = val
+	}
+
+	if o.varsFile != "" {
+		var data []byte
+		var fileVars map[string]interface{}
+		if data, err = os.ReadFile(o.varsFile); err == nil {
+			fileVars, err = decodeYAMLMap(data)
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to load variables file %q, error: %v", o.varsFile, err)
+			return
+		}
+		normalizeValues(fileVars)
+		vars = mergeMaps(vars, fileVars)
+	}
+
+	for _, pair := range o.varArgs {
+		key, val, ok := strings.Cut(pair, "=")
+		if key = strings.TrimSpace(key); !ok || key == "" {
+			err = fmt.Errorf("invalid variable %q, it should be key=value", pair)
+			return
+		}
+		vars[key] = val
+	}
+	return
+}
+
+// loadEnv returns the environment variables in the allowlist which are set
+func (o *option) loadEnv() (env map[string]string) {
+	env = make(map[string]string, len(o.env))
+	for _, name := range o.env {
+		if val, ok := os.LookupEnv(name); ok {
+			env[name] = val
+		}
+	}
+	return
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandWithContext(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), "name: a\nkind: cli")
+	writeFile(t, filepath.Join(dir, "b.yaml"), "name: b\nkind: web")
+	varsFile := filepath.Join(dir, "vars", "vars.yaml")
+	writeFile(t, varsFile, "version: v0.1.0\nrepo: linuxsuren/fake\nbuild:\n  date: 2022-01-02")
+	tpl := filepath.Join(dir, "README.tpl")
+	t.Setenv("YAML_README_TOKEN", "secret")
+	t.Setenv("YAML_README_BRANCH", "master")
+
+	tests := []struct {
+		name      string
+		template  string
+		args      []string
+		expect    string
+		wantError string
+	}{{
+		name:     "variables of items",
+		template: "{{range .}}{{.name}}{{end}}-{{len .}}-{{len .Items}}-{{.Vars.version}}-{{.Vars.build.date}}-{{variable \"version\"}}",
+		args:     []string{"--vars-file", varsFile, "--var", "version=v1.0.0", "--var", "empty="},
+		expect:   "ab-2-2-v1.0.0-2022-01-02-v1.0.0",
+	}, {
+		name:     "variables of groups",
+		template: "{{range $key, $val := .}}{{$key}}{{end}}-{{len .Items}}-{{len .Groups}}-{{.Vars.repo}}",
+		args:     []string{"--vars-file", varsFile, "--group-by", "kind"},
+		expect:   "cliweb-2-2-linuxsuren/fake",
+	}, {
+		name:     "variables of ordered groups",
+		template: "{{range .}}{{.Key}}{{end}}-{{range .Groups}}{{.Key}}{{end}}-{{.Vars.repo}}",
+		args:     []string{"--var", "repo=a=b", "--group-by", "kind", "--group-order", "!key"},
+		expect:   "webcli-webcli-a=b",
+	}, {
+		name:     "allowed environment variables",
+		template: "{{.Env.YAML_README_BRANCH}}-{{.Env.YAML_README_TOKEN}}-{{len .Env}}",
+		args:     []string{"--env", "YAML_README_BRANCH,YAML_README_FAKE"},
+		expect:   "master--1",
+	}, {
+		name:      "invalid variable",
+		template:  "{{len .}}",
+		args:      []string{"--var", "version"},
+		wantError: `invalid variable "version", it should be key=value`,
+	}, {
+		name:      "missing variables file",
+		template:  "{{len .}}",
+		args:      []string{"--vars-file", filepath.Join(dir, "fake.yaml")},
+		wantError: "failed to load variables file",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeFile(t, tpl, tt.template)
+			cmd := newRootCommand()
+			buf := bytes.NewBuffer([]byte{})
+			cmd.SetOut(buf)
+			cmd.SetArgs(append([]string{"-t", tpl, "-p", filepath.Join(dir, "*.yaml"), "--include-header=false"}, tt.args...))
+			err := cmd.Execute()
+			if tt.wantError != "" {
+				if assert.NotNil(t, err) {
+					assert.Contains(t, err.Error(), tt.wantError)
+				}
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expect, buf.String())
+		})
+	}
+}
+
+func TestVariablesFromHeaderAndConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), "name: a")
+	tpl := filepath.Join(dir, "README.tpl")
+	writeFile(t, tpl, "#!yaml-readme --var version=v1 --var name=header\n{{.Vars.version}}-{{.Vars.name}}-{{.Vars.repo}}")
+	configFile := filepath.Join(dir, "config.yaml")
+	writeFile(t, configFile, `jobs:
+- name: docs
+  pattern: `+filepath.Join(dir, "*.yaml")+`
+  template: `+tpl+`
+  header: false
+  variables:
+    repo: config
+    version: v0
+`)
+
+	cmd := newRootCommand()
+	buf := bytes.NewBuffer([]byte{})
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--config", configFile})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "v1-header-config", buf.String())
+
+	cmd = newRootCommand()
+	buf = bytes.NewBuffer([]byte{})
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--config", configFile, "--job", "docs", "--var", "name=flag"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "v0-flag-config", buf.String())
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// listSeparator separates the values of a list column in the CSV files
+const listSeparator = ";"
+
+// dateLayouts are the accepted layouts of a date column, the dates are formatted as the first one
+var dateLayouts = []string{time.DateOnly, "2006/01/02", time.RFC3339}
+
+var csvTypeHints = map[string]bool{
+	"string": true, "int": true, "float": true, "bool": true, "date": true, "list": true,
+}
+
+// csvColumn is a column of the CSV header, the type hint follows the name, for example: year:int
+type csvColumn struct {
+	name     string
+	typeHint string
+}
+
+func decodeCSV(data []byte) ([]map[string]interface{}, error) {
+	return decodeTable(data, ',')
+}
+
+func decodeTSV(data []byte) ([]map[string]interface{}, error) {
+	return decodeTable(data, '\t')
+}
+
+// decodeTable turns each row of a table into an item, the header row is the keys.
+// The type hints of the columns are: string, int, float, bool, date and list.
+func decodeTable(data []byte, comma rune) (items []map[string]interface{}, err error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.Comma = comma
+	reader.TrimLeadingSpace = true
+
+	var header []string
+	if header, err = reader.Read(); err != nil {
+		if err == io.EOF {
+			err = nil
+		}
+		return
+	}
+
+	var columns []csvColumn
+	if columns, err = parseCSVHeader(header); err != nil {
+		return
+	}
+
+	for {
+		var row []string
+		if row, err = reader.Read(); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			return
+		}
+
+		line, _ := reader.FieldPos(0)
+		item := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			var val interface{}
+			if val, err = column.parse(row[i]); err != nil {
+				err = fmt.Errorf("line %d, column %q: %v", line, column.name, err)
+				return
+			}
+			if val != nil {
+				item[column.name] = val
+			}
+		}
+		items = append(items, item)
+	}
+	return
+}
+
+func parseCSVHeader(header []string) (columns []csvColumn, err error) {
+	names := make(map[string]bool, len(header))
+	for _, field := range header {
+		column := csvColumn{name: strings.TrimSpace(field), typeHint: "string"}
+		if index := strings.LastIndex(column.name, ":"); index > 0 {
+			column.typeHint = strings.ToLower(strings.TrimSpace(column.name[index+1:]))
+			column.name = strings.TrimSpace(column.name[:index])
+		}
+
+		switch {
+		case column.name == "":
+			err = fmt.Errorf("empty column name in the header")
+		case names[column.name]:
+			err = fmt.Errorf("duplicated column %q in the header", column.name)
+		case !csvTypeHints[column.typeHint]:
+			err = fmt.Errorf("unknown type %q of column %q", column.typeHint, column.name)
+		}
+		if err != nil {
+			return
+		}
+		names[column.name] = true
+		columns = append(columns, column)
+	}
+	return
+}
+
+// parse converts a cell according to the type hint, the empty cells of the typed columns are missing values
+func (c csvColumn) parse(cell string) (val interface{}, err error) {
+	cell = strings.TrimSpace(cell)
+	if c.typeHint == "string" {
+		val = cell
+		return
+	}
+	if cell == "" {
+		return
+	}
+
+	switch c.typeHint {
+	case "int":
+		val, err = strconv.Atoi(cell)
+	case "float":
+		val, err = strconv.ParseFloat(cell, 64)
+	case "bool":
+		val, err = strconv.ParseBool(cell)
+	case "date":
+		err = fmt.Errorf("invalid date %q", cell)
+		for _, layout := range dateLayouts {
+			if date, parseErr := time.Parse(layout, cell); parseErr == nil {
+				val, err = date.Format(dateLayouts[0]), nil
+				break
+			}
+		}
+	case "list":
+		var list []interface{}
+		for _, item := range strings.Split(cell, listSeparator) {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		val = list
+	}
+	return
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_decodeTable(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		data      string
+		wantItems []map[string]interface{}
+		wantError string
+	}{{
+		name: "typed columns",
+		file: "a.csv",
+		data: "\ufeffname,year:int,rate:float,active:bool,date:date,tags:list\n" +
+			"a,2022,1.5,true,2022/01/02,x; y\n" +
+			`"b, c",,,,,` + "\n",
+		wantItems: []map[string]interface{}{{
+			"name": "a", "year": 2022, "rate": 1.5, "active": true, "date": "2022-01-02", "tags": []interface{}{"x", "y"},
+		}, {
+			"name": "b, c",
+		}},
+	}, {
+		name:      "TSV",
+		file:      "a.tsv",
+		data:      "name\tyear:INT\na\t2022\n",
+		wantItems: []map[string]interface{}{{"name": "a", "year": 2022}},
+	}, {
+		name: "empty file",
+		file: "a.csv",
+	}, {
+		name:      "invalid value",
+		file:      "a.csv",
+		data:      "name,year:int\na,2022\nb,two\n",
+		wantError: `line 3, column "year": strconv.Atoi: parsing "two": invalid syntax`,
+	}, {
+		name:      "invalid date",
+		file:      "a.csv",
+		data:      "date:date\n01-02\n",
+		wantError: `line 2, column "date": invalid date "01-02"`,
+	}, {
+		name:      "unknown type",
+		file:      "a.csv",
+		data:      "name:in\na\n",
+		wantError: `unknown type "in" of column "name"`,
+	}, {
+		name:      "duplicated column",
+		file:      "a.csv",
+		data:      "name,name:string\na,a\n",
+		wantError: `duplicated column "name" in the header`,
+	}, {
+		name:      "wrong number of fields",
+		file:      "a.csv",
+		data:      "name,year\na\n",
+		wantError: "record on line 2: wrong number of fields",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := decodeItems(tt.file, []byte(tt.data))
+			if tt.wantError != "" {
+				assert.EqualError(t, err, tt.wantError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantItems, items)
+		})
+	}
+}
+
+func TestCommandWithCSV(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "tools.csv"), "name,year:int,kind\nb,2021,cli\na,2022,cli\nc,2021,web\n")
+	tpl := filepath.Join(dir, "README.tpl")
+	writeFile(t, tpl, "{{- range $key, $val := .}}{{$key}}:{{range $val}}{{.name}}{{.filename}}{{end}};{{end}}")
+
+	cmd := newRootCommand()
+	buf := bytes.NewBuffer([]byte{})
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "*.csv"), "--group-by", "year", "--sort-by", "name",
+		"--include-header=false"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "2021:btoolsctools;2022:atools;", buf.String())
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/yuin/goldmark"
+	"gopkg.in/yaml.v2"
+)
+
+// itemDecoder parses the data of a file into items
+type itemDecoder func(data []byte) (items []map[string]interface{}, err error)
+
+// itemDecoders are the decoders of the file extensions, the others are parsed as YAML
+var itemDecoders = map[string]itemDecoder{
+	".json":     decodeJSON,
+	".toml":     singleItem(decodeTOML),
+	".md":       singleItem(decodeMarkdown),
+	".markdown": singleItem(decodeMarkdown),
+	".csv":      decodeCSV,
+	".tsv":      decodeTSV,
+}
+
+// decodeItems parses the data of a file into items according to the file extension
+func decodeItems(file string, data []byte) (items []map[string]interface{}, err error) {
+	decoder, ok := itemDecoders[strings.ToLower(filepath.Ext(file))]
+	if !ok {
+		decoder = decodeYAML
+	}
+	if items, err = decoder(data); err == nil {
+		for _, item := range items {
+			normalizeValues(item)
+		}
+	}
+	return
+}
+
+// singleItem makes a decoder which parses a file as one item
+func singleItem(decode func(data []byte) (map[string]interface{}, error)) itemDecoder {
+	return func(data []byte) (items []map[string]interface{}, err error) {
+		var item map[string]interface{}
+		if item, err = decode(data); err == nil {
+			if item == nil {
+				item = make(map[string]interface{})
+			}
+			items = []map[string]interface{}{item}
+		}
+		return
+	}
+}
+
+// decodeYAML parses the documents of a YAML file, each document is an item or a list of items
+func decodeYAML(data []byte) (items []map[string]interface{}, err error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	var docs int
+	for {
+		var doc interface{}
+		if err = decoder.Decode(&doc); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			return
+		}
+		docs++
+
+		var docItems []map[string]interface{}
+		if docItems, err = toItems(doc); err != nil {
+			err = fmt.Errorf("document %d: %v", docs, err)
+			return
+		}
+		items = append(items, docItems...)
+	}
+
+	// an empty file is an empty item
+	if docs == 0 {
+		items = []map[string]interface{}{{}}
+	}
+	return
+}
+
+// decodeYAMLMap parses a YAML document as one item
+func decodeYAMLMap(data []byte) (item map[string]interface{}, err error) {
+	item = make(map[string]interface{})
+	err = yaml.Unmarshal(data, item)
+	return
+}
+
+// decodeJSON parses a JSON file, the top level is an item or a list of items
+func decodeJSON(data []byte) (items []map[string]interface{}, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err = decoder.Decode(&doc); err == nil {
+		items, err = toItems(normalizeValue(doc))
+	}
+	return
+}
+
+// toItems converts a document into items, it should be a map or a list of maps
+func toItems(doc interface{}) (items []map[string]interface{}, err error) {
+	switch val := doc.(type) {
+	case nil:
+	case map[string]interface{}:
+		items = []map[string]interface{}{val}
+	case map[interface{}]interface{}:
+		item := make(map[string]interface{}, len(val))
+		for k, v := range val {
+			item[fmt.Sprint(k)] = v
+		}
+		items = []map[string]interface{}{item}
+	case []interface{}:
+		for i, element := range val {
+			var elementItems []map[string]interface{}
+			if elementItems, err = toItems(element); err == nil && len(elementItems) != 1 {
+				err = fmt.Errorf("the element %d of the list is not a map", i)
+			}
+			if err != nil {
+				return
+			}
+			items = append(items, elementItems...)
+		}
+	default:
+		err = fmt.Errorf("the top level should be a map or a list of maps instead of %T", doc)
+	}
+	return
+}
+
+func decodeTOML(data []byte) (item map[string]interface{}, err error) {
+	if err = toml.Unmarshal(data, &item); err == nil {
+		normalizeValues(item)
+	}
+	return
+}
+
+var (
+	// the closing delimiter is a line of its own, the front matter is empty if it follows the opening one
+	yamlFrontMatterReg = regexp.MustCompile(`(?s)^---\r?\n(?:(.*?)\r?\n)?---[ \t]*(?:\r?\n|$)`)
+	tomlFrontMatterReg = regexp.MustCompile(`(?s)^\+\+\+\r?\n(?:(.*?)\r?\n)?\+\+\+[ \t]*(?:\r?\n|$)`)
+	excerptSeparator   = "<!--more-->"
+)
+
+// decodeMarkdown parses the YAML or TOML front matter of a Markdown file as the item,
+// the body is available as the variables: content, contentHTML and excerpt.
+func decodeMarkdown(data []byte) (item map[string]interface{}, err error) {
+	body := data
+	if match := yamlFrontMatterReg.FindSubmatch(data); match != nil {
+		item, err = decodeYAMLMap(match[1])
+		body = data[len(match[0]):]
+	} else if match = tomlFrontMatterReg.FindSubmatch(data); match != nil {
+		item, err = decodeTOML(match[1])
+		body = data[len(match[0]):]
+	}
+	if err != nil {
+		err = fmt.Errorf("failed to parse the front matter, error: %v", err)
+		return
+	}
+	if item == nil {
+		// there is no front matter, or it's empty
+		item = make(map[string]interface{})
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	if err = goldmark.Convert(body, buf); err != nil {
+		return
+	}
+
+	content := string(body)
+	item["content"] = content
+	// it's safe to output the rendered HTML without escaping
+	item["contentHTML"] = template.HTML(buf.String())
+	item["excerpt"] = getExcerpt(content)
+	return
+}
+
+// getExcerpt returns the text before the separator '<!--more-->', or the first paragraph
+func getExcerpt(content string) string {
+	if index := strings.Index(content, excerptSeparator); index >= 0 {
+		return strings.TrimSpace(content[:index])
+	}
+
+	content = strings.TrimSpace(strings.ReplaceAll(content, "\r\n", "\n"))
+	if index := strings.Index(content, "\n\n"); index >= 0 {
+		content = content[:index]
+	}
+	return content
+}
+
+// normalizeValues converts the values to the same types of all the decoders, for example: json.Number to int,
+// and the nested maps of the YAML decoder to string-keyed maps
+func normalizeValues(item map[string]interface{}) {
+	for key, val := range item {
+		item[key] = normalizeValue(val)
+	}
+}
+
+func normalizeValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return int(i)
+		}
+		f, _ := v.Float64()
+		return f
+	case int64:
+		return int(v)
+	case time.Time:
+		// the local date and time of TOML have no time zone
+		switch v.Location().String() {
+		case "date-local":
+			return v.Format(time.DateOnly)
+		case "time-local":
+			return v.Format(time.TimeOnly)
+		case "datetime-local":
+			return v.Format("2006-01-02T15:04:05")
+		}
+		return v.Format(time.RFC3339)
+	case map[string]interface{}:
+		normalizeValues(v)
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			object[fmt.Sprint(key)] = normalizeValue(value)
+		}
+		return object
+	case []interface{}:
+		for i := range v {
+			v[i] = normalizeValue(v[i])
+		}
+	case []map[string]interface{}:
+		for i := range v {
+			normalizeValues(v[i])
+		}
+	}
+	return val
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_decodeItems(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		data     string
+		wantItem map[string]interface{}
+		hasError bool
+	}{{
+		name:     "YAML",
+		file:     "a.yaml",
+		data:     "name: a\nyear: 2022",
+		wantItem: map[string]interface{}{"name": "a", "year": 2022},
+	}, {
+		name: "nested YAML maps",
+		file: "a.yaml",
+		data: "links:\n  github: a\n  1: b\nreleases:\n- version: v1\n  assets: {linux: a.tar.gz}",
+		wantItem: map[string]interface{}{
+			"links": map[string]interface{}{"github": "a", "1": "b"},
+			"releases": []interface{}{map[string]interface{}{
+				"version": "v1", "assets": map[string]interface{}{"linux": "a.tar.gz"},
+			}},
+		},
+	}, {
+		name:     "unknown extension as YAML",
+		file:     "a.yml",
+		data:     "name: a",
+		wantItem: map[string]interface{}{"name": "a"},
+	}, {
+		name:     "empty YAML",
+		file:     "a.yaml",
+		wantItem: map[string]interface{}{},
+	}, {
+		name: "JSON",
+		file: "a.JSON",
+		data: `{"name": "a", "year": 2022, "rate": 1.5, "tags": [1, "b"], "links": {"stars": 10}}`,
+		wantItem: map[string]interface{}{"name": "a", "year": 2022, "rate": 1.5, "tags": []interface{}{1, "b"},
+			"links": map[string]interface{}{"stars": 10}},
+	}, {
+		name:     "invalid JSON",
+		file:     "a.json",
+		data:     `{"name": `,
+		hasError: true,
+	}, {
+		name: "TOML",
+		file: "a.toml",
+		data: "name = 'a'\nyear = 2022\ndate = 2022-01-02\nupdated = 2022-01-02T03:04:05Z\n[links]\nstars = 10",
+		wantItem: map[string]interface{}{"name": "a", "year": 2022, "date": "2022-01-02", "updated": "2022-01-02T03:04:05Z",
+			"links": map[string]interface{}{"stars": 10}},
+	}, {
+		name: "Markdown with YAML front matter",
+		file: "a.md",
+		data: "---\nname: a\nyear: 2022\n---\nThe first paragraph.\n\nThe *second* one.\n",
+		wantItem: map[string]interface{}{"name": "a", "year": 2022,
+			"content":     "The first paragraph.\n\nThe *second* one.\n",
+			"contentHTML": template.HTML("<p>The first paragraph.</p>\n<p>The <em>second</em> one.</p>\n"),
+			"excerpt":     "The first paragraph."},
+	}, {
+		name: "Markdown with TOML front matter and excerpt separator",
+		file: "a.markdown",
+		data: "+++\nname = 'a'\n+++\nline one\nline two\n<!--more-->\nrest",
+		wantItem: map[string]interface{}{"name": "a",
+			"content":     "line one\nline two\n<!--more-->\nrest",
+			"contentHTML": template.HTML("<p>line one\nline two</p>\n<!-- raw HTML omitted -->\n<p>rest</p>\n"),
+			"excerpt":     "line one\nline two"},
+	}, {
+		name: "Markdown without front matter",
+		file: "a.md",
+		data: "# Title",
+		wantItem: map[string]interface{}{
+			"content":     "# Title",
+			"contentHTML": template.HTML("<h1>Title</h1>\n"),
+			"excerpt":     "# Title"},
+	}, {
+		name: "Markdown with the delimiter in a value of the front matter",
+		file: "a.md",
+		data: "---\ntitle: Hello ---\ntags: [a]\n---\nbody",
+		wantItem: map[string]interface{}{"title": "Hello ---", "tags": []interface{}{"a"},
+			"content":     "body",
+			"contentHTML": template.HTML("<p>body</p>\n"),
+			"excerpt":     "body"},
+	}, {
+		name: "Markdown with empty YAML front matter",
+		file: "a.md",
+		data: "---\n---\nbody",
+		wantItem: map[string]interface{}{
+			"content":     "body",
+			"contentHTML": template.HTML("<p>body</p>\n"),
+			"excerpt":     "body"},
+	}, {
+		name: "Markdown with empty TOML front matter",
+		file: "a.md",
+		data: "+++\r\n+++\r\nbody",
+		wantItem: map[string]interface{}{
+			"content":     "body",
+			"contentHTML": template.HTML("<p>body</p>\n"),
+			"excerpt":     "body"},
+	}, {
+		name:     "Markdown with invalid front matter",
+		file:     "a.md",
+		data:     "---\nname: [a\n---\n",
+		hasError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := decodeItems(tt.file, []byte(tt.data))
+			if tt.hasError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, []map[string]interface{}{tt.wantItem}, items)
+		})
+	}
+}
+
+func Test_decodeMultipleItems(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		data      string
+		wantItems []map[string]interface{}
+		hasError  bool
+	}{{
+		name: "multiple YAML documents",
+		file: "a.yaml",
+		data: "name: a\n---\n---\nname: b\n1: c",
+		wantItems: []map[string]interface{}{{
+			"name": "a",
+		}, {
+			"name": "b", "1": "c",
+		}},
+	}, {
+		name: "YAML list",
+		file: "a.yaml",
+		data: "- name: a\n- name: b\n---\nname: c",
+		wantItems: []map[string]interface{}{{
+			"name": "a",
+		}, {
+			"name": "b",
+		}, {
+			"name": "c",
+		}},
+	}, {
+		name: "JSON list",
+		file: "a.json",
+		data: `[{"name": "a"}, {"name": "b", "year": 2022}]`,
+		wantItems: []map[string]interface{}{{
+			"name": "a",
+		}, {
+			"name": "b", "year": 2022,
+		}},
+	}, {
+		name:     "scalar document",
+		file:     "a.yaml",
+		data:     "name: a\n---\nfake",
+		hasError: true,
+	}, {
+		name:     "list of scalars",
+		file:     "a.json",
+		data:     `[{"name": "a"}, "b"]`,
+		hasError: true,
+	}, {
+		name:     "invalid document",
+		file:     "a.yaml",
+		data:     "name: a\n---\nname: [b",
+		hasError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := decodeItems(tt.file, []byte(tt.data))
+			if tt.hasError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantItems, items)
+		})
+	}
+}
+
+func Test_loadItemsFromMultipleDocuments(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), "name: a\n---\nname: b\nignore: true\n---\nname: c")
+
+	items, err := loadItems([]string{filepath.Join(dir, "*.yaml")})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(items))
+	items, err = filterItems(items, defaultFilter)
+	assert.Nil(t, err)
+	assert.Equal(t, []map[string]interface{}{{
+		"name": "a", "filename": "a", "parentname": filepath.Base(dir), "fullpath": filepath.Join(dir, "a.yaml"), "docindex": 0,
+	}, {
+		"name": "c", "filename": "a", "parentname": filepath.Base(dir), "fullpath": filepath.Join(dir, "a.yaml"), "docindex": 2,
+	}}, items)
+}
+
+func Test_loadItemsFromMixedSources(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), "name: a\nyear: 2021")
+	writeFile(t, filepath.Join(dir, "b.json"), `{"name": "b", "year": 2022}`)
+	writeFile(t, filepath.Join(dir, "c.toml"), "name = 'c'\nyear = 2021")
+	writeFile(t, filepath.Join(dir, "d.md"), "---\nname: d\nyear: 2022\n---\nbody")
+
+	items, err := readItems([]string{filepath.Join(dir, "*.{yaml,json,toml,md}")}, func(file string, err error) {
+		t.Errorf("failed to parse file %q, error: %v", file, err)
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(items))
+	assert.Equal(t, "body", items[3]["content"])
+	assert.Equal(t, "d", items[3]["filename"])
+	groups := groupItems(items, []string{"year"})
+	if assert.Equal(t, 2, len(groups)) {
+		assert.Equal(t, "2021", groups[0].Key)
+		assert.Equal(t, 2, len(groups[0].Items))
+		assert.Equal(t, "2022", groups[1].Key)
+		assert.Equal(t, 2, len(groups[1].Items))
+	}
+}
+
+func TestCommandWithNestedFields(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), "name: a\nlinks:\n  github: n2\nmeta:\n  category: cli")
+	writeFile(t, filepath.Join(dir, "b.yaml"), "name: b\nlinks:\n  github: n1\nmeta:\n  category: web")
+	writeFile(t, filepath.Join(dir, "c.yaml"), "name: c\nlinks:\n  github: n3\nmeta:\n  category: cli")
+	tpl := filepath.Join(dir, "README.tpl")
+
+	writeFile(t, tpl, "{{range .}}{{.name}}{{toJson .links}}{{end}}")
+	cmd := newRootCommand()
+	buf := bytes.NewBuffer([]byte{})
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "*.yaml"), "--sort-by", "links.github", "--include-header=false"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, `b{"github":"n1"}a{"github":"n2"}c{"github":"n3"}`, buf.String())
+
+	cmd = newRootCommand()
+	buf = bytes.NewBuffer([]byte{})
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "*.yaml"), "--sort-by", "links.github", "--include-header=false",
+		"--engine", "html"})
+	assert.Nil(t, cmd.Execute())
+	// the quotes are escaped by the HTML template
+	assert.Equal(t, "b{&#34;github&#34;:&#34;n1&#34;}a{&#34;github&#34;:&#34;n2&#34;}c{&#34;github&#34;:&#34;n3&#34;}", buf.String())
+
+	writeFile(t, tpl, "{{range $key, $val := .}}{{$key}}:{{range $val}}{{.name}}{{end}};{{end}}")
+	cmd = newRootCommand()
+	buf = bytes.NewBuffer([]byte{})
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "*.yaml"), "--group-by", "meta.category", "--include-header=false"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "cli:ac;web:b;", buf.String())
+}
@@ -35,6 +35,9 @@ while [ $# -gt 0 ]; do
     --includeHeader=*)
       header="${1#*=}"
       ;;
+    --check=*)
+      check="${1#*=}"
+      ;;
     *)
       printf "***************************\n"
       printf "* Error: Invalid argument.*\n"
@@ -50,6 +53,12 @@ then
   hd i "$tool"
 fi
 
+if [ "$check" = "true" ]
+then
+  yaml-readme -p "$pattern" --sort-by "$sortby" --group-by "$groupby" --template "$template" --include-header="$header" --output "$output" --check
+  exit $?
+fi
+
 yaml-readme -p "$pattern" --sort-by "$sortby" --group-by "$groupby" --template "$template" --include-header="$header" --output "$output"
 if [ $? -eq 0 ]
 then
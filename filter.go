@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/vm"
+)
+
+// defaultFilter skips the items which have 'ignore: true', an explicit filter takes its place
+const defaultFilter = `ignore != true`
+
+// filterItems returns the items which match the expression, for example: status == "active" && year >= 2020.
+// The fields of an item are the variables of the expression, the undefined ones are nil. An item does not match if
+// the expression fails on its undefined fields, such as comparing nil with a number.
+func filterItems(items []map[string]interface{}, filter string) (matchedItems []map[string]interface{}, err error) {
+	var program *vm.Program
+	if program, err = expr.Compile(filter, expr.AllowUndefinedVariables(), expr.AsBool()); err != nil {
+		err = fmt.Errorf("invalid filter %q, error: %v", filter, err)
+		return
+	}
+	fields := &identifiers{}
+	node := program.Node()
+	ast.Walk(&node, fields)
+
+	matchedItems = make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		var matched interface{}
+		if matched, err = expr.Run(program, item); err != nil {
+			if fields.undefinedIn(item) {
+				err = nil
+				continue
+			}
+			err = fmt.Errorf("failed to filter item %q, error: %v", item["fullpath"], err)
+			return
+		}
+		if ok, isBool := matched.(bool); !isBool {
+			err = fmt.Errorf("failed to filter item %q, error: the result should be a bool instead of %T", item["fullpath"], matched)
+			return
+		} else if ok {
+			matchedItems = append(matchedItems, item)
+		}
+	}
+	return
+}
+
+// identifiers collects the variables of an expression
+type identifiers []string
+
+func (i *identifiers) Visit(node *ast.Node) {
+	if identifier, ok := (*node).(*ast.IdentifierNode); ok {
+		*i = append(*i, identifier.Value)
+	}
+}
+
+// undefinedIn checks if any of the variables is undefined in the item
+func (i *identifiers) undefinedIn(item map[string]interface{}) bool {
+	for _, name := range *i {
+		if val, ok := item[name]; !ok || val == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_filterItems(t *testing.T) {
+	items := []map[string]interface{}{
+		{"name": "a", "status": "active", "year": 2021, "tags": []interface{}{"go"}, "fullpath": "a.yaml"},
+		{"name": "b", "status": "archived", "year": 2022, "links": map[string]interface{}{"github": "b"}, "fullpath": "b.yaml"},
+		{"name": "c", "status": "active", "year": 2019, "fullpath": "c.yaml"},
+		{"name": "d", "fullpath": "d.yaml"},
+		{"name": "e", "status": "active", "fullpath": "e.yaml"},
+	}
+
+	tests := []struct {
+		name      string
+		filter    string
+		want      []string
+		wantError string
+	}{{
+		name:   "conditions",
+		filter: `status == "active" && year >= 2020`,
+		want:   []string{"a"},
+	}, {
+		name:   "undefined field",
+		filter: `year == nil || year < 2020`,
+		want:   []string{"c", "d", "e"},
+	}, {
+		name:   "nested field",
+		filter: `links?.github == "b"`,
+		want:   []string{"b"},
+	}, {
+		name:   "list field",
+		filter: `"go" in (tags ?? [])`,
+		want:   []string{"a"},
+	}, {
+		name:   "no matched items",
+		filter: `false`,
+		want:   []string{},
+	}, {
+		name:      "invalid expression",
+		filter:    `status ==`,
+		wantError: `invalid filter "status ==", error: `,
+	}, {
+		name:      "not a boolean",
+		filter:    `name`,
+		wantError: `failed to filter item "a.yaml", error: `,
+	}, {
+		name:   "invalid operation of the undefined field",
+		filter: `year >= 2020`,
+		want:   []string{"a", "b"},
+	}, {
+		name:      "invalid operation of the defined field",
+		filter:    `name >= 2020`,
+		wantError: `failed to filter item "a.yaml", error: `,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matchedItems, err := filterItems(items, tt.filter)
+			if tt.wantError != "" {
+				if assert.NotNil(t, err) {
+					assert.Contains(t, err.Error(), tt.wantError)
+				}
+				return
+			}
+			assert.Nil(t, err)
+
+			names := []string{}
+			for _, item := range matchedItems {
+				names = append(names, item["name"].(string))
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
+
+func TestCommandWithFilter(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), "name: a\nstatus: active")
+	writeFile(t, filepath.Join(dir, "b.yaml"), "name: b\nstatus: archived")
+	writeFile(t, filepath.Join(dir, "c.yaml"), "name: c\nstatus: active\nignore: true")
+	tpl := filepath.Join(dir, "README.tpl")
+	writeFile(t, tpl, "{{range .}}{{.name}}{{end}}")
+
+	tests := []struct {
+		name   string
+		args   []string
+		expect string
+	}{{
+		name:   "skip the ignored items by default",
+		expect: "ab",
+	}, {
+		name:   "the filter takes the place of the default one",
+		args:   []string{"--filter", `status == "active"`},
+		expect: "ac",
+	}, {
+		name:   "skip the ignored items explicitly",
+		args:   []string{"--filter", `ignore != true && status == "active"`},
+		expect: "a",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newRootCommand()
+			buf := bytes.NewBuffer([]byte{})
+			cmd.SetOut(buf)
+			cmd.SetArgs(append([]string{"-t", tpl, "-p", filepath.Join(dir, "*.yaml"), "--include-header=false"}, tt.args...))
+			assert.Nil(t, cmd.Execute())
+			assert.Equal(t, tt.expect, buf.String())
+		})
+	}
+}
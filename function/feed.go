@@ -1,6 +1,7 @@
 package function
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -33,42 +34,69 @@ func GetLatestPost(items []*gofeed.Item) *gofeed.Item {
 	return latest
 }
 
-func GetFeedLatestPost(feedLink string, defaultContent string) (output string) {
-	fp := gofeed.NewParser()
-	feed, err := fp.ParseURL(feedLink)
+// errNoPost means there is no post in a feed
+var errNoPost = errors.New("there is no post in the feed")
 
-	if err != nil {
+func GetFeedLatestPost(feedLink string, defaultContent string) (output string) {
+	var err error
+	if output, err = GetFeedLatestPostE(feedLink); err != nil {
+		if errors.Is(err, errNoPost) {
+			return "feed parsed failed"
+		}
 		logger.Printf("parse feed %s failed: %s\n", feedLink, err)
-		return fmt.Sprintf("[%s](%s)", defaultContent, defaultContent)
-
+		output = fmt.Sprintf("[%s](%s)", defaultContent, defaultContent)
 	}
-	// get latest post
-	latest := GetLatestPost(feed.Items)
-	if latest == nil {
-		return "feed parsed failed"
+	return
+}
+
+// GetFeedLatestPostE is GetFeedLatestPost which returns the error instead of the default content
+func GetFeedLatestPostE(feedLink string) (output string, err error) {
+	var latest *gofeed.Item
+	if latest, err = getFeedLatestPost(feedLink); err != nil {
+		return
 	}
+
 	title := strings.ReplaceAll(latest.Title, "|", " ")
 	link := latest.Link
 	output = fmt.Sprintf(`[%s](%s)`, title, link)
 	if IsLastServenDays(latest.PublishedParsed) {
 		output += "![news](https://github.com/ChanceYu/front-end-rss/blob/master/assets/new.png?raw=true)"
 	}
-	return output
+	return
 }
 
 func GetFeedLatestPostPublishedDate(feedLink string) (output string) {
-	fp := gofeed.NewParser()
-	feed, err := fp.ParseURL(feedLink)
-	if err != nil {
-		return ""
+	var err error
+	if output, err = GetFeedLatestPostPublishedDateE(feedLink); errors.Is(err, errNoPost) {
+		output = "feed parsed failed"
+	}
+	return
+}
+
+// GetFeedLatestPostPublishedDateE is GetFeedLatestPostPublishedDate which returns the error
+func GetFeedLatestPostPublishedDateE(feedLink string) (output string, err error) {
+	var latest *gofeed.Item
+	if latest, err = getFeedLatestPost(feedLink); err != nil {
+		return
 	}
-	// get latest post
-	latest := GetLatestPost(feed.Items)
 
-	if latest == nil {
-		return "feed parsed failed"
+	if latest.PublishedParsed == nil {
+		err = fmt.Errorf("the latest post of feed %s has no published date", feedLink)
+		return
 	}
-	publishTime := latest.PublishedParsed.Format(time.RFC3339)
+	output = latest.PublishedParsed.Format(time.RFC3339)
+	return
+}
 
-	return publishTime
+func getFeedLatestPost(feedLink string) (latest *gofeed.Item, err error) {
+	var feed *gofeed.Feed
+	if feed, err = gofeed.NewParser().ParseURL(feedLink); err != nil {
+		err = fmt.Errorf("failed to parse feed %s: %w", feedLink, err)
+		return
+	}
+
+	if latest = GetLatestPost(feed.Items); latest == nil {
+		err = fmt.Errorf("%w: %s", errNoPost, feedLink)
+	}
+	return
 }
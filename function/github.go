@@ -19,13 +19,15 @@ import (
 
 // PrintContributors from a GitHub repository
 func PrintContributors(owner, repo string) (output string) {
-	api := fmt.Sprintf("https://api.github.com/repos/%s/%s/contributors", owner, repo)
+	output, _ = PrintContributorsE(owner, repo)
+	return
+}
 
-	var (
-		contributors []map[string]interface{}
-		err          error
-	)
+// PrintContributorsE is PrintContributors which returns the error
+func PrintContributorsE(owner, repo string) (output string, err error) {
+	api := fmt.Sprintf("https://api.github.com/repos/%s/%s/contributors", owner, repo)
 
+	var contributors []map[string]interface{}
 	if contributors, err = ghRequestAsSlice(api); err == nil {
 		var text string
 		group := 6
@@ -44,91 +46,88 @@ func PrintContributors(owner, repo string) (output string) {
 	return
 }
 
-// GetRepoStars awesome ops func
-func GetStarLicense(owner, repo string) (rst string) {
-	pro, err := GetProject(owner, repo)
-	if err != nil {
-		panic(err)
-	} else {
-		var spdxID string
-		if license := pro.GetLicense(); license != nil {
-			spdxID = license.GetSPDXID()
-		} else {
-			spdxID = "N/A"
-		}
-		fmt.Printf("%v|%v|%v|%v", spdxID, pro.GetStargazersCount(), pro.GetCreatedAt().Format("2006-01-02"), pro.GetPushedAt().Format("2006-01-02"))
+// GetStarLicense returns the license, stars, creation and last pushed dates of a GitHub repository separated by '|'
+func GetStarLicense(owner, repo string) (rst string, err error) {
+	var pro *github.Repository
+	if pro, err = getRepository(owner, repo); err == nil {
+		rst = fmt.Sprintf("%v|%v|%v|%v", licenseSPDXID(pro), pro.GetStargazersCount(),
+			pro.GetCreatedAt().Format("2006-01-02"), pro.GetPushedAt().Format("2006-01-02"))
 	}
 	return
 }
 
 // GetRepoStars Get the stars of a GitHub repository
-func GetRepoStars(owner, repo string) (star int) {
-	pro, err := GetProject(owner, repo)
-	if err != nil {
-		panic(err)
-	} else {
+func GetRepoStars(owner, repo string) (star int, err error) {
+	var pro *github.Repository
+	if pro, err = getRepository(owner, repo); err == nil {
 		star = pro.GetStargazersCount()
 	}
 	return
 }
 
 // GetRepoForks Get the forks of a GitHub repository
-func GetRepoForks(owner, repo string) (fork int) {
-	pro, err := GetProject(owner, repo)
-	if err != nil {
-		panic(err)
-	} else {
+func GetRepoForks(owner, repo string) (fork int, err error) {
+	var pro *github.Repository
+	if pro, err = getRepository(owner, repo); err == nil {
 		fork = pro.GetForksCount()
 	}
 	return
 }
 
-// GetRepoWatchers Get the watchers of a GitHub repository
-func GetRepoLicenses(owner, repo string) (spdxID string) {
-	pro, err := GetProject(owner, repo)
-	if err != nil {
-		panic(err)
-	} else {
-		if license := pro.GetLicense(); license != nil {
-			spdxID = license.GetSPDXID()
-		} else {
-			spdxID = "N/A"
-		}
+// GetRepoLicenses Get the SPDX ID of the license of a GitHub repository
+func GetRepoLicenses(owner, repo string) (spdxID string, err error) {
+	var pro *github.Repository
+	if pro, err = getRepository(owner, repo); err == nil {
+		spdxID = licenseSPDXID(pro)
 	}
 	return
 }
 
-// GetRepoCreateAt Get the watchers of a GitHub repository
-func GetRepoCreateAt(owner, repo string) (create string) {
-	pro, err := GetProject(owner, repo)
-	if err != nil {
-		panic(err)
-	} else {
+// GetRepoCreateAt Get the creation date of a GitHub repository
+func GetRepoCreateAt(owner, repo string) (create string, err error) {
+	var pro *github.Repository
+	if pro, err = getRepository(owner, repo); err == nil {
 		create = pro.GetCreatedAt().Format("2006-01-02")
 	}
 	return
 }
 
-// GetRepoWatchers Get the watchers of a GitHub repository
-func GetRepoPushAt(owner, repo string) (lastupdate interface{}) {
-	pro, err := GetProject(owner, repo)
-	if err != nil {
-		panic(err)
-	} else {
+// GetRepoPushAt Get the last pushed date of a GitHub repository
+func GetRepoPushAt(owner, repo string) (lastupdate interface{}, err error) {
+	var pro *github.Repository
+	if pro, err = getRepository(owner, repo); err == nil {
 		lastupdate = pro.GetPushedAt().Format("2006-01-02")
 	}
 	return
 }
 
+// getRepository gets a GitHub repository, the error tells which repository it is
+func getRepository(owner, repo string) (pro *github.Repository, err error) {
+	if pro, err = GetProject(owner, repo); err != nil {
+		err = fmt.Errorf("failed to get repository %s/%s, error: %v", owner, repo, err)
+	}
+	return
+}
+
+// licenseSPDXID returns the SPDX ID of the license of a repository, or N/A if there is no license
+func licenseSPDXID(pro *github.Repository) string {
+	if license := pro.GetLicense(); license != nil {
+		return license.GetSPDXID()
+	}
+	return "N/A"
+}
+
 // PrintPages prints the repositories which enabled pages
 func PrintPages(owner string) (output string) {
-	api := fmt.Sprintf("https://api.github.com/users/%s/repos?type=owner&per_page=100&sort=updated&username=%s", owner, owner)
+	output, _ = PrintPagesE(owner)
+	return
+}
 
-	var (
-		repos []map[string]interface{}
-		err   error
-	)
+// PrintPagesE is PrintPages which returns the error
+func PrintPagesE(owner string) (output string, err error) {
+	api := fmt.Sprintf("https://api.github.com/users/%s/repos?type=owner&per_page=100&sort=updated&username=%s", owner, owner)
 
+	var repos []map[string]interface{}
 	if repos, err = ghRequestAsSlice(api); err == nil {
 		var text string
 		for i := 0; i < len(repos); i++ {
@@ -160,8 +159,15 @@ func ghRequest(api string) (data []byte, err error) {
 			req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
 		}
 
-		if resp, err = http.DefaultClient.Do(req); err == nil && resp.StatusCode == http.StatusOK {
-			data, err = io.ReadAll(resp.Body)
+		if resp, err = http.DefaultClient.Do(req); err == nil {
+			defer func() {
+				_ = resp.Body.Close()
+			}()
+			if resp.StatusCode == http.StatusOK {
+				data, err = io.ReadAll(resp.Body)
+			} else {
+				err = fmt.Errorf("unexpected status %q of %s", resp.Status, api)
+			}
 		}
 	}
 	return
@@ -226,6 +232,22 @@ var contributorsTpl = `{{- range $i, $val := .}}
 
 // GitHubUsersLink parses a text and try to make the potential GitHub IDs be links
 func GitHubUsersLink(ids, sep string) (links string) {
+	links = gitHubUsersLink(ids, sep, GithubUserLink)
+	return
+}
+
+// GitHubUsersLinkE is GitHubUsersLink which returns the first error
+func GitHubUsersLinkE(ids, sep string) (links string, err error) {
+	links = gitHubUsersLink(ids, sep, func(id string, bio bool) (link string) {
+		if err == nil {
+			link, err = GithubUserLinkE(id, bio)
+		}
+		return
+	})
+	return
+}
+
+func gitHubUsersLink(ids, sep string, userLink func(id string, bio bool) string) (links string) {
 	if sep == "" {
 		sep = " "
 	}
@@ -233,7 +255,7 @@ func GitHubUsersLink(ids, sep string) (links string) {
 	splits := strings.Split(ids, sep)
 	var items []string
 	for _, item := range splits {
-		items = append(items, GithubUserLink(strings.TrimSpace(item), false))
+		items = append(items, userLink(strings.TrimSpace(item), false))
 	}
 
 	// having additional whitespace it's an ASCII character
@@ -246,6 +268,15 @@ func GitHubUsersLink(ids, sep string) (links string) {
 
 // GithubUserLink makes a GitHub user link
 func GithubUserLink(id string, bio bool) (link string) {
+	var err error
+	if link, err = GithubUserLinkE(id, bio); err != nil {
+		link = id
+	}
+	return
+}
+
+// GithubUserLinkE is GithubUserLink which returns the error instead of the ID
+func GithubUserLinkE(id string, bio bool) (link string, err error) {
 	link = id
 	if strings.Contains(id, " ") { // only handle the valid GitHub ID
 		return
@@ -254,17 +285,14 @@ func GithubUserLink(id string, bio bool) (link string) {
 	// return the original text if there are Markdown style link exist
 	if hasLink(id) {
 		if bio {
-			return GithubUserLink(GetIDFromGHLink(id), bio)
+			return GithubUserLinkE(GetIDFromGHLink(id), bio)
 		}
 		return
 	}
 
 	api := fmt.Sprintf("https://api.github.com/users/%s", id)
 
-	var (
-		err  error
-		data map[string]interface{}
-	)
+	var data map[string]interface{}
 	if data, err = ghRequestAsMap(api); err == nil {
 		link = fmt.Sprintf("[%s](%s)", data["name"], data["html_url"])
 		if bioText, ok := data["bio"]; ok && bio && bioText != nil {
@@ -290,16 +318,19 @@ func GetIDFromGHLink(link string) string {
 
 // PrintUserAsTable generates a table for a GitHub user
 func PrintUserAsTable(id string) (result string) {
+	result, _ = PrintUserAsTableE(id)
+	return
+}
+
+// PrintUserAsTableE is PrintUserAsTable which returns the error
+func PrintUserAsTableE(id string) (result string, err error) {
 	api := fmt.Sprintf("https://api.github.com/users/%s", id)
 
 	result = `|||
 |---|---|
 `
 
-	var (
-		err  error
-		data map[string]interface{}
-	)
+	var data map[string]interface{}
 	if data, err = ghRequestAsMap(api); err == nil {
 		result = result + addWithEmpty("Name", "name", data) +
 			addWithEmpty("Location", "location", data) +
@@ -365,3 +365,72 @@ func TestPrintPages(t *testing.T) {
 		})
 	}
 }
+
+func TestFunctionsWithError(t *testing.T) {
+	defer gock.Off()
+	gock.New("https://api.github.com").Get("/users/fake").Reply(http.StatusNotFound)
+	gock.New("https://api.github.com").Get("/repos/linuxsuren/fake/contributors").Reply(http.StatusNotFound)
+	mockGitHubUser("linuxsuren")
+
+	link, err := GithubUserLinkE("fake", false)
+	assert.EqualError(t, err, `unexpected status "404 Not Found" of https://api.github.com/users/fake`)
+	assert.Equal(t, "fake", link)
+
+	link, err = GitHubUsersLinkE("linuxsuren fake", " ")
+	assert.NotNil(t, err)
+	assert.Equal(t, "[Rick](https://github.com/LinuxSuRen) fake", link)
+
+	_, err = PrintContributorsE("linuxsuren", "fake")
+	assert.NotNil(t, err)
+	_, err = PrintUserAsTableE("linuxsuren-fake")
+	assert.NotNil(t, err)
+	_, err = PrintPagesE("linuxsuren-fake")
+	assert.NotNil(t, err)
+}
+
+func TestRepoFunctions(t *testing.T) {
+	defer gock.Off()
+	for i := 0; i < 6; i++ {
+		gock.New("https://api.github.com").Get("/repos/linuxsuren/yaml-readme").Reply(http.StatusOK).JSON(map[string]interface{}{
+			"stargazers_count": 10,
+			"forks_count":      2,
+			"license":          map[string]string{"spdx_id": "MIT"},
+			"created_at":       "2022-01-02T03:04:05Z",
+			"pushed_at":        "2023-04-05T06:07:08Z",
+		})
+	}
+
+	star, err := GetRepoStars("linuxsuren", "yaml-readme")
+	assert.Nil(t, err)
+	assert.Equal(t, 10, star)
+	fork, err := GetRepoForks("linuxsuren", "yaml-readme")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, fork)
+	license, err := GetRepoLicenses("linuxsuren", "yaml-readme")
+	assert.Nil(t, err)
+	assert.Equal(t, "MIT", license)
+	create, err := GetRepoCreateAt("linuxsuren", "yaml-readme")
+	assert.Nil(t, err)
+	assert.Equal(t, "2022-01-02", create)
+	push, err := GetRepoPushAt("linuxsuren", "yaml-readme")
+	assert.Nil(t, err)
+	assert.Equal(t, "2023-04-05", push)
+	custom, err := GetStarLicense("linuxsuren", "yaml-readme")
+	assert.Nil(t, err)
+	assert.Equal(t, "MIT|10|2022-01-02|2023-04-05", custom)
+
+	gock.New("https://api.github.com").Get("/repos/linuxsuren/fake").Times(6).Reply(http.StatusNotFound)
+	for _, fn := range []func(string, string) (interface{}, error){
+		func(owner, repo string) (interface{}, error) { return GetRepoStars(owner, repo) },
+		func(owner, repo string) (interface{}, error) { return GetRepoForks(owner, repo) },
+		func(owner, repo string) (interface{}, error) { return GetRepoLicenses(owner, repo) },
+		func(owner, repo string) (interface{}, error) { return GetRepoCreateAt(owner, repo) },
+		GetRepoPushAt,
+		func(owner, repo string) (interface{}, error) { return GetStarLicense(owner, repo) },
+	} {
+		_, err = fn("linuxsuren", "fake")
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "failed to get repository linuxsuren/fake, error: ")
+		}
+	}
+}
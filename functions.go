@@ -0,0 +1,475 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/sprig"
+	"github.com/linuxsuren/yaml-readme/function"
+)
+
+// sprigDocs is the documentation of the Sprig functions
+const sprigDocs = "http://masterminds.github.io/sprig/"
+
+// templateFunction is a function of the template with its documentation
+type templateFunction struct {
+	name        string
+	fn          interface{}
+	args        []string
+	description string
+	example     string
+	// network indicates if the function requests a remote service, such as the GitHub API
+	network bool
+	// strictFn is the variant of fn for the strict mode, it returns the error instead of an empty or default value
+	strictFn interface{}
+}
+
+// functionContext is the state of a rendering which some of the built-in functions rely on
+type functionContext struct {
+	// readmeTpl is the content of the template
+	readmeTpl         string
+	groupNum, itemNum uint
+	vars              map[string]interface{}
+	// include renders another template file with the data
+	include func(path string, data interface{}) (template.HTML, error)
+	// strict indicates if the functions return the errors
+	strict bool
+	// fallback replaces the results of the network functions which failed, the failures are reported to fail
+	fallback string
+	fail     func(err error)
+}
+
+// builtinFunctions returns the built-in functions of the template
+func builtinFunctions(ctx functionContext) []templateFunction {
+	readmeTpl, groupNum, itemNum, vars := ctx.readmeTpl, ctx.groupNum, ctx.itemNum, ctx.vars
+	include := ctx.include
+	if include == nil {
+		include = func(path string, data interface{}) (template.HTML, error) {
+			return "", fmt.Errorf("cannot include %q out of a template file", path)
+		}
+	}
+
+	functions := []templateFunction{{
+		name: "printHelp", args: []string{"cmd"},
+		fn: func(cmd string) (output string) {
+			var err error
+			var data []byte
+			if data, err = exec.Command(cmd, "--help").Output(); err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "failed to run command", cmd)
+			} else {
+				output = fmt.Sprintf(`%s
+%s
+%s`, "```shell", string(data), "```")
+			}
+			return
+		},
+		description: "Print the help text of a command",
+		example:     `{{printHelp "hd"}}`,
+		strictFn: func(cmd string) (output string, err error) {
+			var data []byte
+			if data, err = exec.Command(cmd, "--help").Output(); err != nil {
+				err = fmt.Errorf("failed to run command %q, error: %v", cmd, err)
+			} else {
+				output = fmt.Sprintf("%s\n%s\n%s", "```shell", string(data), "```")
+			}
+			return
+		},
+	}, {
+		name: "lenItemNum",
+		fn: func() uint {
+			return itemNum
+		},
+		description: "Return the number of the items",
+		example:     `{{lenItemNum}}`,
+	}, {
+		name: "lenGroupNum",
+		fn: func() uint {
+			return groupNum
+		},
+		description: "Return the number of the groups",
+		example:     `{{lenGroupNum}}`,
+	}, {
+		name: "updateDesc", args: []string{"owner", "repo"},
+		fn: func(owner, repo string) string {
+			err := function.UpdateRepoDescription(owner, repo, repoDescription(itemNum))
+			if err != nil {
+				fmt.Printf("failed to update repo description, error: %v\n", err)
+				os.Exit(1)
+			}
+			return ""
+		},
+		description: "Update the description of a repository with the number of items, it requires GITHUB_TOKEN",
+		example:     `{{updateDesc "linuxsuren" "yaml-readme"}}`,
+		network:     true,
+		strictFn: func(owner, repo string) (output string, err error) {
+			if err = function.UpdateRepoDescription(owner, repo, repoDescription(itemNum)); err != nil {
+				err = fmt.Errorf("failed to update the description of %s/%s, error: %v", owner, repo, err)
+			}
+			return
+		},
+	}, {
+		name: "printToc",
+		fn: func() string {
+			return generateTOC(readmeTpl)
+		},
+		description: "Print the TOC of the template file",
+		example:     `{{printToc}}`,
+	}, {
+		name: "printContributors", args: []string{"owner", "repo"},
+		fn: func(owner, repo string) template.HTML {
+			return template.HTML(function.PrintContributors(owner, repo))
+		},
+		description: "Print all the contributors of a repository",
+		example:     `{{printContributors "linuxsuren" "yaml-readme"}}`,
+		network:     true,
+		strictFn: func(owner, repo string) (output template.HTML, err error) {
+			var contributors string
+			if contributors, err = function.PrintContributorsE(owner, repo); err == nil {
+				output = template.HTML(contributors)
+			}
+			return
+		},
+	}, {
+		name: "printStarHistory", args: []string{"owner", "repo"},
+		fn: func(owner, repo string) string {
+			return printStarHistory(owner, repo)
+		},
+		description: "Print the star history chart of a repository",
+		example:     `{{printStarHistory "linuxsuren" "yaml-readme"}}`,
+	}, {
+		name: "printVisitorCount", args: []string{"id"},
+		fn: func(id string) string {
+			return fmt.Sprintf(`![Visitor Count](https://profile-counter.glitch.me/%s/count.svg)`, id)
+		},
+		description: "Print the visitor count chart of a repository",
+		example:     `{{printVisitorCount "repo-id"}}`,
+	}, {
+		name: "printPages", args: []string{"owner"},
+		fn: func(owner string) string {
+			return function.PrintPages(owner)
+		},
+		description: "Print all the repositories which enabled pages",
+		example:     `{{printPages "linuxsuren"}}`,
+		network:     true,
+		strictFn:    function.PrintPagesE,
+	}, {
+		name: "getFeedLatestPost", args: []string{"feedLink", "defaultContent"},
+		fn: func(feedLink string, defaultContent string) string {
+			return function.GetFeedLatestPost(feedLink, defaultContent)
+		},
+		description: "Print the latest post of a feed as a link, or the default content if it fails",
+		example:     `{{getFeedLatestPost "https://example.com/feed.xml" "https://example.com"}}`,
+		network:     true,
+		strictFn: func(feedLink string, defaultContent string) (string, error) {
+			return function.GetFeedLatestPostE(feedLink)
+		},
+	}, {
+		name: "getFeedLatestPostPublishedDate", args: []string{"feedLink"},
+		fn: func(feedLink string) string {
+			return function.GetFeedLatestPostPublishedDate(feedLink)
+		},
+		description: "Print the published date of the latest post of a feed",
+		example:     `{{getFeedLatestPostPublishedDate "https://example.com/feed.xml"}}`,
+		network:     true,
+		strictFn:    function.GetFeedLatestPostPublishedDateE,
+	}, {
+		name: "goUrlDecode", args: []string{"link"},
+		fn: func(link string) string {
+			decodeUrl, err := url.QueryUnescape(link)
+			if err != nil {
+				return link
+			}
+			return decodeUrl
+		},
+		description: "Decode a URL-encoded text, or keep it as it is if it's invalid",
+		example:     `{{goUrlDecode "a%20b"}}`,
+		strictFn:    url.QueryUnescape,
+	}, {
+		name: "variable", args: []string{"name"},
+		fn: func(name string) string {
+			if val, ok := vars[name]; ok && val != nil {
+				return fmt.Sprint(val)
+			}
+			return ""
+		},
+		description: "Return a variable from the flags, the variables file or the config",
+		example:     `{{variable "repo"}}`,
+		strictFn: func(name string) (output string, err error) {
+			if val, ok := vars[name]; ok && val != nil {
+				output = fmt.Sprint(val)
+			} else {
+				err = fmt.Errorf("variable %q is not defined", name)
+			}
+			return
+		},
+	}, {
+		name: "include", args: []string{"path", "data"}, fn: include,
+		description: "Render another template file with the data, the path is relative to the including file",
+		example:     `{{include "_row.tpl" .}}`,
+	}, {
+		name: "render", args: []string{"data"}, fn: dataRender,
+		description: "Make the value be readable, turn true to :white_check_mark: and false to :x:",
+		example:     `{{render true}}`,
+		strictFn: func(data interface{}) (output string, err error) {
+			switch data.(type) {
+			case bool, string:
+				output = dataRender(data)
+			default:
+				err = fmt.Errorf("cannot render %v of type %T, it should be a bool or string", data, data)
+			}
+			return
+		},
+	}, {
+		name: "gh", args: []string{"id", "bio"}, fn: function.GithubUserLink,
+		description: "Render a GitHub user to be a link, with the bio optionally",
+		example:     `{{gh "linuxsuren" true}}`,
+		network:     true,
+		strictFn:    function.GithubUserLinkE,
+	}, {
+		name: "ghs", args: []string{"ids", "sep"}, fn: function.GitHubUsersLink,
+		description: "Render multiple GitHub users to be links",
+		example:     `{{ghs "linuxsuren, linuxsuren" ","}}`,
+		network:     true,
+		strictFn:    function.GitHubUsersLinkE,
+	}, {
+		name: "ghEmoji", args: []string{"user"}, fn: function.GitHubEmojiLink,
+		description: "Print a Markdown style link of a GitHub user with Emoji",
+		example:     `{{ghEmoji "linuxsuren"}}`,
+	}, {
+		name: "link", args: []string{"text", "link"}, fn: function.Link,
+		description: "Print a Markdown style link",
+		example:     `{{link "text" "link"}}`,
+	}, {
+		name: "linkOrEmpty", args: []string{"text", "link"}, fn: function.LinkOrEmpty,
+		description: "Print a Markdown style link, or empty if the text is empty",
+		example:     `{{linkOrEmpty "text" "link"}}`,
+	}, {
+		name: "twitterLink", args: []string{"user"}, fn: function.TwitterLink,
+		description: "Print a Markdown style image link of a Twitter user",
+		example:     `{{twitterLink "linuxsuren"}}`,
+	}, {
+		name: "youTubeLink", args: []string{"id"}, fn: function.YouTubeLink,
+		description: "Print a Markdown style image link of a YouTube channel",
+		example:     `{{youTubeLink "@linuxsuren"}}`,
+	}, {
+		name: "gstatic", args: []string{"id"}, fn: function.GStatic,
+		description: "Return the image URL of a known website, such as twitter or youtube",
+		example:     `{{gstatic "twitter"}}`,
+		strictFn: func(id string) (output string, err error) {
+			if id != "twitter" && id != "youtube" {
+				err = fmt.Errorf("unknown website %q, it should be twitter or youtube", id)
+				return
+			}
+			output = function.GStatic(id)
+			return
+		},
+	}, {
+		name: "ghID", args: []string{"link"}, fn: function.GetIDFromGHLink,
+		description: "Return the GitHub ID from a Markdown style link",
+		example:     `{{ghID "[Rick](https://github.com/linuxsuren)"}}`,
+	}, {
+		name: "ghStar", args: []string{"owner", "repo"}, fn: function.GetRepoStars,
+		description: "Return the number of stars of a repository",
+		example:     `{{ghStar "linuxsuren" "yaml-readme"}}`,
+		network:     true,
+	}, {
+		name: "ghFork", args: []string{"owner", "repo"}, fn: function.GetRepoForks,
+		description: "Return the number of forks of a repository",
+		example:     `{{ghFork "linuxsuren" "yaml-readme"}}`,
+		network:     true,
+	}, {
+		name: "ghCreate", args: []string{"owner", "repo"}, fn: function.GetRepoCreateAt,
+		description: "Return the creation date of a repository",
+		example:     `{{ghCreate "linuxsuren" "yaml-readme"}}`,
+		network:     true,
+	}, {
+		name: "ghUpdate", args: []string{"owner", "repo"}, fn: function.GetRepoPushAt,
+		description: "Return the last pushed date of a repository",
+		example:     `{{ghUpdate "linuxsuren" "yaml-readme"}}`,
+		network:     true,
+	}, {
+		name: "ghLicense", args: []string{"owner", "repo"}, fn: function.GetRepoLicenses,
+		description: "Return the SPDX ID of the license of a repository",
+		example:     `{{ghLicense "linuxsuren" "yaml-readme"}}`,
+		network:     true,
+	}, {
+		name: "ghCustom", args: []string{"owner", "repo"}, fn: function.GetStarLicense,
+		description: "Print the license, stars, creation and last pushed dates of a repository separated by '|'",
+		example:     `{{ghCustom "linuxsuren" "yaml-readme"}}`,
+		network:     true,
+	}, {
+		name: "printGHTable", args: []string{"id"}, fn: function.PrintUserAsTable,
+		description: "Print a table of a GitHub user",
+		example:     `{{printGHTable "linuxsuren"}}`,
+		network:     true,
+		strictFn:    function.PrintUserAsTableE,
+	}}
+
+	if ctx.strict {
+		for i, f := range functions {
+			if f.strictFn != nil {
+				functions[i].fn = f.strictFn
+			}
+		}
+	}
+	if ctx.fallback != "" {
+		for i, f := range functions {
+			if f.network && returnsError(f.fn) {
+				functions[i].fn = withFallback(f.name, f.fn, ctx.fallback, ctx.fail)
+			}
+		}
+	}
+	return functions
+}
+
+// errorType is the type of the error interface
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// returnsError checks if a function returns a value and an error
+func returnsError(fn interface{}) bool {
+	fnType := reflect.TypeOf(fn)
+	return fnType.NumOut() == 2 && fnType.Out(1) == errorType
+}
+
+// withFallback wraps a function which returns a value and an error, the wrapper returns the fallback instead of the
+// error and reports it to fail
+func withFallback(name string, fn interface{}, fallback string, fail func(err error)) interface{} {
+	fnValue := reflect.ValueOf(fn)
+	fnType := fnValue.Type()
+	in := make([]reflect.Type, fnType.NumIn())
+	for i := range in {
+		in[i] = fnType.In(i)
+	}
+	outType := reflect.TypeOf((*interface{})(nil)).Elem()
+
+	wrapperType := reflect.FuncOf(in, []reflect.Type{outType}, fnType.IsVariadic())
+	return reflect.MakeFunc(wrapperType, func(args []reflect.Value) []reflect.Value {
+		var results []reflect.Value
+		if fnType.IsVariadic() {
+			results = fnValue.CallSlice(args)
+		} else {
+			results = fnValue.Call(args)
+		}
+
+		result := reflect.New(outType).Elem()
+		if err, _ := results[1].Interface().(error); err != nil {
+			if fail != nil {
+				fail(fmt.Errorf("error calling %s: %v", name, err))
+			}
+			result.Set(reflect.ValueOf(fallback))
+		} else {
+			result.Set(results[0])
+		}
+		return []reflect.Value{result}
+	}).Interface()
+}
+
+// repoDescription returns the description of a repository with the number of items
+func repoDescription(itemNum uint) string {
+	return fmt.Sprintf("🧰 记录每一个与运维相关的优秀项目，⚗️ 项目内表格通过 GitHub Action 自动生成，📥 当前收录项目 %d 个。", itemNum)
+}
+
+// sprigFunctions returns the functions from Sprig, the documentation is a link
+func sprigFunctions() (functions []templateFunction) {
+	for name, fn := range sprig.FuncMap() {
+		functions = append(functions, templateFunction{name: name, fn: fn, description: "See " + sprigDocs})
+	}
+	return
+}
+
+func toFuncMap(functions []templateFunction) template.FuncMap {
+	funcMap := make(template.FuncMap, len(functions))
+	for _, f := range functions {
+		funcMap[f.name] = f.fn
+	}
+	return funcMap
+}
+
+// signature returns the signature of the function, for example: link(text string, link string) string
+func (f templateFunction) signature() string {
+	fnType := reflect.TypeOf(f.fn)
+	params := make([]string, fnType.NumIn())
+	for i := range params {
+		paramType := fnType.In(i).String()
+		if fnType.IsVariadic() && i == len(params)-1 {
+			paramType = "..." + fnType.In(i).Elem().String()
+		}
+		if len(f.args) == len(params) {
+			paramType = f.args[i] + " " + paramType
+		}
+		params[i] = paramType
+	}
+
+	results := make([]string, fnType.NumOut())
+	for i := range results {
+		results[i] = fnType.Out(i).String()
+	}
+	result := strings.Join(results, ", ")
+	if len(results) > 1 {
+		result = "(" + result + ")"
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s(%s) %s", f.name, strings.Join(params, ", "), result))
+}
+
+// functionInfo is the documentation of a function for printing
+type functionInfo struct {
+	Name        string `json:"name"`
+	Signature   string `json:"signature"`
+	Description string `json:"description"`
+	Example     string `json:"example,omitempty"`
+	Network     bool   `json:"network"`
+	Source      string `json:"source"`
+}
+
+// printFunctions prints the built-in and Sprig functions, the built-in ones win if there are the same names
+func printFunctions(stdout io.Writer, format string) (err error) {
+	var functions []functionInfo
+	found := make(map[string]bool)
+	for _, source := range []struct {
+		name      string
+		functions []templateFunction
+	}{{"built-in", builtinFunctions(functionContext{})}, {"sprig", sprigFunctions()}} {
+		var sourceFunctions []functionInfo
+		for _, f := range source.functions {
+			if !found[f.name] {
+				found[f.name] = true
+				sourceFunctions = append(sourceFunctions, functionInfo{Name: f.name, Signature: f.signature(),
+					Description: f.description, Example: f.example, Network: f.network, Source: source.name})
+			}
+		}
+		sort.Slice(sourceFunctions, func(i, j int) bool {
+			return sourceFunctions[i].Name < sourceFunctions[j].Name
+		})
+		functions = append(functions, sourceFunctions...)
+	}
+
+	if format == "json" {
+		err = printJSON(stdout, functions)
+		return
+	}
+
+	rows := make([][]string, len(functions))
+	for i, f := range functions {
+		var network string
+		if f.Network {
+			network = "yes"
+		}
+		rows[i] = []string{f.Name, f.Signature, f.Description, f.Example, network, f.Source}
+		if format == "markdown" {
+			for j := 1; j < 4; j += 2 {
+				if rows[i][j] != "" {
+					rows[i][j] = "`" + rows[i][j] + "`"
+				}
+			}
+		}
+	}
+	err = printTable(stdout, format, []string{"Name", "Signature", "Description", "Example", "Network", "Source"}, rows)
+	return
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/h2non/gock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_signature(t *testing.T) {
+	functions := make(map[string]templateFunction)
+	for _, f := range append(builtinFunctions(functionContext{}), sprigFunctions()...) {
+		functions[f.name] = f
+	}
+
+	assert.Equal(t, "link(text string, link string) string", functions["link"].signature())
+	assert.Equal(t, "printToc() string", functions["printToc"].signature())
+	assert.Equal(t, "ghUpdate(owner string, repo string) (interface {}, error)", functions["ghUpdate"].signature())
+	assert.Equal(t, "printContributors(owner string, repo string) template.HTML", functions["printContributors"].signature())
+	assert.Equal(t, "list(...interface {}) []interface {}", functions["list"].signature())
+	assert.Equal(t, "toDate(string, string) time.Time", functions["toDate"].signature())
+
+	for _, f := range builtinFunctions(functionContext{}) {
+		assert.NotEmpty(t, f.description, f.name)
+		assert.True(t, strings.HasPrefix(f.example, "{{"+f.name), f.name)
+		assert.True(t, len(f.args) == 0 || strings.Contains(f.signature(), f.args[0]+" "), f.name)
+	}
+}
+
+func Test_printFunctions(t *testing.T) {
+	buf := bytes.NewBuffer([]byte{})
+	assert.Nil(t, printFunctions(buf, "json"))
+	var functions []functionInfo
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &functions))
+	assert.Equal(t, functionInfo{
+		Name: "getFeedLatestPost", Signature: "getFeedLatestPost(feedLink string, defaultContent string) string",
+		Description: "Print the latest post of a feed as a link, or the default content if it fails",
+		Example:     `{{getFeedLatestPost "https://example.com/feed.xml" "https://example.com"}}`,
+		Network:     true, Source: "built-in",
+	}, functions[0])
+	assert.Equal(t, "sprig", functions[len(functions)-1].Source)
+
+	buf.Reset()
+	assert.Nil(t, printFunctions(buf, "markdown"))
+	assert.Contains(t, buf.String(), "| Name | Signature | Description | Example | Network | Source |\n|---|---|---|---|---|---|\n")
+	assert.Contains(t, buf.String(), "| `link` | `link(text string, link string) string` | Print a Markdown style link | "+
+		"`{{link \"text\" \"link\"}}` |  | built-in |\n")
+	assert.Contains(t, buf.String(), "| `upper` | `upper(string) string` | See http://masterminds.github.io/sprig/ |  |  | sprig |\n")
+
+	cmd := newRootCommand()
+	buf.Reset()
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--print-functions"})
+	assert.Nil(t, cmd.Execute())
+	assert.True(t, strings.HasPrefix(buf.String(), "NAME "))
+	assert.Regexp(t, `\nghStar +ghStar\(owner string, repo string\) \(int, error\) +Return the number of stars of a repository +`+
+		`\{\{ghStar "linuxsuren" "yaml-readme"\}\} +yes +built-in\n`, buf.String())
+}
+
+func Test_strictFunctions(t *testing.T) {
+	functions := make(map[string]interface{})
+	for _, f := range builtinFunctions(functionContext{strict: true, vars: map[string]interface{}{"repo": "a/b"}}) {
+		functions[f.name] = f.fn
+		if f.strictFn != nil {
+			fnType := reflect.TypeOf(f.fn)
+			assert.Equal(t, reflect.TypeOf(f.strictFn), fnType, f.name)
+			assert.Equal(t, 2, fnType.NumOut(), f.name)
+			assert.Equal(t, "error", fnType.Out(fnType.NumOut()-1).String(), f.name)
+		}
+	}
+
+	variable := functions["variable"].(func(string) (string, error))
+	val, err := variable("repo")
+	assert.Nil(t, err)
+	assert.Equal(t, "a/b", val)
+	_, err = variable("fake")
+	assert.EqualError(t, err, `variable "fake" is not defined`)
+
+	render := functions["render"].(func(interface{}) (string, error))
+	val, err = render(true)
+	assert.Nil(t, err)
+	assert.Equal(t, ":white_check_mark:", val)
+	_, err = render(1)
+	assert.EqualError(t, err, "cannot render 1 of type int, it should be a bool or string")
+
+	_, err = functions["goUrlDecode"].(func(string) (string, error))("%zz")
+	assert.NotNil(t, err)
+	_, err = functions["gstatic"].(func(string) (string, error))("fake")
+	assert.NotNil(t, err)
+	_, err = functions["printHelp"].(func(string) (string, error))("yaml-readme-fake-command")
+	assert.NotNil(t, err)
+
+	// the functions do not change without the strict mode
+	for _, f := range builtinFunctions(functionContext{}) {
+		if f.name == "variable" {
+			assert.Equal(t, "", f.fn.(func(string) string)("fake"))
+		}
+	}
+}
+
+func Test_withFallback(t *testing.T) {
+	var failures []error
+	fail := func(err error) {
+		failures = append(failures, err)
+	}
+	count := withFallback("count", func(owner, repo string) (int, error) {
+		if repo == "fake" {
+			return 0, errors.New("not found")
+		}
+		return 10, nil
+	}, "N/A", fail).(func(string, string) interface{})
+	assert.Equal(t, 10, count("linuxsuren", "yaml-readme"))
+	assert.Equal(t, "N/A", count("linuxsuren", "fake"))
+
+	join := withFallback("join", func(items ...string) (string, error) {
+		return strings.Join(items, ","), nil
+	}, "N/A", fail).(func(...string) interface{})
+	assert.Equal(t, "a,b", join("a", "b"))
+	assert.Equal(t, []error{errors.New("error calling count: not found")}, failures)
+
+	functions := make(map[string]templateFunction)
+	for _, f := range builtinFunctions(functionContext{fallback: "N/A"}) {
+		functions[f.name] = f
+	}
+	assert.Equal(t, "ghStar(owner string, repo string) interface {}", functions["ghStar"].signature())
+	assert.Equal(t, "printContributors(owner string, repo string) template.HTML", functions["printContributors"].signature())
+}
+
+func TestFallbackCommand(t *testing.T) {
+	defer gock.Off()
+	dir := t.TempDir()
+	items := filepath.Join(dir, "items", "*.yaml")
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a\nrepo: fake")
+	content := `{{range .}}{{.name}}: {{ghStar "linuxsuren" .repo}}{{end}}`
+	tpl := filepath.Join(dir, "README.tpl")
+	writeFile(t, tpl, content)
+	writeFile(t, filepath.Join(dir, "docs", "stars.tpl"), fmt.Sprintf("#!yaml-readme -p %s --output %s\n%s",
+		items, filepath.Join(dir, "stars.md"), content))
+	configFile := filepath.Join(dir, "config.yaml")
+	writeFile(t, configFile, fmt.Sprintf("jobs:\n- name: stars\n  pattern: %s\n  template: %s\n  header: false\n"+
+		"  fallback: N/A", items, tpl))
+	args := []string{"-t", tpl, "-p", items, "--include-header=false"}
+
+	gock.New("https://api.github.com").Get("/repos/linuxsuren/fake").Persist().Reply(http.StatusNotFound)
+	cmd := newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "error calling ghStar: failed to get repository linuxsuren/fake, error: ")
+	}
+
+	tests := []struct {
+		name string
+		args []string
+	}{{
+		name: "flags",
+		args: append(args, "--fallback", "N/A"),
+	}, {
+		name: "config file",
+		args: []string{"--config", configFile},
+	}, {
+		name: "render all",
+		args: []string{"render", "--all", filepath.Join(dir, "docs"), "--fallback", "N/A"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, errOut := bytes.NewBuffer([]byte{}), bytes.NewBuffer([]byte{})
+			cmd := newRootCommand()
+			cmd.SetOut(out)
+			cmd.SetErr(errOut)
+			cmd.SetArgs(tt.args)
+			assert.Nil(t, cmd.Execute())
+			assert.Equal(t, "1 function calls failed and were rendered as \"N/A\":\n"+
+				"  - error calling ghStar: failed to get repository linuxsuren/fake, error: "+
+				"GET https://api.github.com/repos/linuxsuren/fake: 404  []\n", errOut.String())
+		})
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "stars.md"))
+	assert.Nil(t, err)
+	assert.True(t, strings.HasSuffix(string(data), "a: N/A"), string(data))
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// gitInfo is the history of a file in the local git repository
+type gitInfo struct {
+	created     string
+	updated     string
+	author      string
+	commitCount int
+}
+
+// errShallowRepository means the history of a shallow clone is incomplete, the git info of it is wrong
+var errShallowRepository = errors.New("it's a shallow clone, the git variables are skipped. " +
+	"Please fetch the whole history, such as 'fetch-depth: 0' of actions/checkout")
+
+// addGitInfo adds the built-in variables gitCreated, gitUpdated, gitAuthor and gitCommitCount to the items from the
+// local git history of the files. The items keep their own values, and the untracked files have none of them.
+// The files in a shallow clone have none of them as well, the failures of git are logged.
+func addGitInfo(items []map[string]interface{}) {
+	dirs := make(map[string][]string)
+	for _, item := range items {
+		if file, ok := item["fullpath"].(string); ok {
+			dir, name := filepath.Split(file)
+			dirs[dir] = append(dirs[dir], name)
+		}
+	}
+
+	infos := make(map[string]*gitInfo)
+	logged := make(map[string]bool)
+	for dir, names := range dirs {
+		dirInfos, err := readGitInfo(dir, names)
+		if err != nil {
+			// the directories of the same repository have the same error
+			if !logged[err.Error()] {
+				logged[err.Error()] = true
+				logger.Printf("failed to read the git history of %q, error: %v\n", dir, err)
+			}
+			continue
+		}
+		for name, info := range dirInfos {
+			infos[filepath.Join(dir, name)] = info
+		}
+	}
+
+	for _, item := range items {
+		file, _ := item["fullpath"].(string)
+		info, ok := infos[filepath.Clean(file)]
+		if !ok {
+			continue
+		}
+
+		fields := map[string]interface{}{
+			"gitCreated": info.created, "gitUpdated": info.updated, "gitAuthor": info.author, "gitCommitCount": info.commitCount,
+		}
+		for key, val := range fields {
+			if _, exist := item[key]; !exist {
+				item[key] = val
+			}
+		}
+	}
+}
+
+// readGitInfo reads the history of the files in a directory by one git command, it works offline.
+// It returns nothing if the directory is not in a git repository, or an error if it's a shallow clone.
+func readGitInfo(dir string, names []string) (infos map[string]*gitInfo, err error) {
+	infos = make(map[string]*gitInfo)
+	if dir == "" {
+		dir = "."
+	}
+
+	var output []byte
+	if output, err = runGit(dir, "rev-parse", "--is-shallow-repository"); err != nil {
+		if strings.Contains(err.Error(), "not a git repository") {
+			err = nil
+		}
+		return
+	}
+	if strings.TrimSpace(string(output)) == "true" {
+		err = errShallowRepository
+		return
+	}
+
+	sort.Strings(names)
+	args := append([]string{"--literal-pathspecs", "-c", "core.quotePath=false",
+		"log", "--relative", "--no-renames", "--name-only", "--format=%x00%aI%x09%an", "--"}, names...)
+	if output, err = runGit(dir, args...); err != nil {
+		return
+	}
+
+	// the commits are listed from the newest to the oldest
+	var date, author string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "\x00") {
+			date, author, _ = strings.Cut(strings.TrimPrefix(line, "\x00"), "\t")
+			// the same format as the dates of the other items
+			if t, err := time.Parse(time.RFC3339, date); err == nil {
+				date = t.Format(time.RFC3339)
+			}
+			continue
+		}
+		if line == "" {
+			continue
+		}
+
+		name := filepath.FromSlash(line)
+		info, ok := infos[name]
+		if !ok {
+			info = &gitInfo{updated: date}
+			infos[name] = info
+		}
+		info.created, info.author = date, author
+		info.commitCount++
+	}
+	return
+}
+
+// runGit runs a git command in the directory, the error has the stderr of git
+func runGit(dir string, args ...string) (output []byte, err error) {
+	if output, err = exec.Command("git", append([]string{"-C", dir}, args...)...).Output(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			err = fmt.Errorf("%v: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+	}
+	return
+}
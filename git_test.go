@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func gitCommit(t *testing.T, dir, author, date string, args ...string) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME="+author, "GIT_AUTHOR_EMAIL="+author+"@example.com",
+		"GIT_COMMITTER_NAME="+author, "GIT_COMMITTER_EMAIL="+author+"@example.com",
+		"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	output, err := cmd.CombinedOutput()
+	assert.Nil(t, err, string(output))
+}
+
+func Test_addGitInfo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	gitCommit(t, dir, "rick", "", "init", "-q")
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a")
+	writeFile(t, filepath.Join(dir, "items", "b.yaml"), "name: b")
+	gitCommit(t, dir, "rick", "2022-01-02T10:00:00Z", "add", ".")
+	gitCommit(t, dir, "rick", "2022-01-02T10:00:00Z", "commit", "-q", "-m", "add a and b")
+	writeFile(t, filepath.Join(dir, "items", "b.yaml"), "name: b\nyear: 2022")
+	gitCommit(t, dir, "morty", "2022-03-04T10:00:00Z", "commit", "-q", "-am", "update b")
+	writeFile(t, filepath.Join(dir, "items", "c.yaml"), "name: c\ngitAuthor: summer")
+
+	items := []map[string]interface{}{
+		{"name": "a", "fullpath": filepath.Join(dir, "items", "a.yaml")},
+		{"name": "b", "fullpath": filepath.Join(dir, "items", "b.yaml")},
+		{"name": "c", "fullpath": filepath.Join(dir, "items", "c.yaml"), "gitAuthor": "summer"},
+		{"name": "d", "fullpath": filepath.Join(t.TempDir(), "d.yaml")},
+	}
+	addGitInfo(items)
+	assert.Equal(t, map[string]interface{}{
+		"name": "a", "fullpath": filepath.Join(dir, "items", "a.yaml"),
+		"gitCreated": "2022-01-02T10:00:00Z", "gitUpdated": "2022-01-02T10:00:00Z", "gitAuthor": "rick", "gitCommitCount": 1,
+	}, items[0])
+	assert.Equal(t, map[string]interface{}{
+		"name": "b", "fullpath": filepath.Join(dir, "items", "b.yaml"),
+		"gitCreated": "2022-01-02T10:00:00Z", "gitUpdated": "2022-03-04T10:00:00Z", "gitAuthor": "rick", "gitCommitCount": 2,
+	}, items[1])
+	// the untracked files have no git info, the own values are kept
+	assert.Equal(t, map[string]interface{}{
+		"name": "c", "fullpath": filepath.Join(dir, "items", "c.yaml"), "gitAuthor": "summer",
+	}, items[2])
+	assert.Equal(t, 2, len(items[3]))
+
+	tpl := filepath.Join(dir, "README.tpl")
+	writeFile(t, tpl, "{{range .}}{{.name}}:{{.gitCommitCount}};{{end}}")
+	cmd := newRootCommand()
+	buf := bytes.NewBuffer([]byte{})
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "items", "*.yaml"), "--include-header=false",
+		"--sort-by", "!gitUpdated,name"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "b:2;a:1;c:;", buf.String())
+}
+
+func Test_addGitInfoOfShallowClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	gitCommit(t, dir, "rick", "", "init", "-q")
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a")
+	gitCommit(t, dir, "rick", "2022-01-02T10:00:00Z", "add", ".")
+	gitCommit(t, dir, "rick", "2022-01-02T10:00:00Z", "commit", "-q", "-m", "add a")
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a\nyear: 2022")
+	gitCommit(t, dir, "morty", "2022-03-04T10:00:00Z", "commit", "-q", "-am", "update a")
+
+	clone := filepath.Join(t.TempDir(), "clone")
+	gitCommit(t, dir, "rick", "", "clone", "-q", "--depth", "1", "file://"+dir, clone)
+
+	logs := bytes.NewBuffer([]byte{})
+	defer func(origin *log.Logger) {
+		logger = origin
+	}(logger)
+	logger = log.New(logs, "", 0)
+
+	// the history of a shallow clone is incomplete, the git info is skipped instead of being wrong
+	items := []map[string]interface{}{
+		{"name": "a", "fullpath": filepath.Join(clone, "items", "a.yaml")},
+		{"name": "b", "fullpath": filepath.Join(t.TempDir(), "b.yaml")},
+	}
+	addGitInfo(items)
+	assert.Equal(t, 2, len(items[0]))
+	assert.Equal(t, 2, len(items[1]))
+	// the directory out of a git repository is not an error
+	assert.Equal(t, fmt.Sprintf("failed to read the git history of %q, error: %v\n",
+		filepath.Join(clone, "items")+string(filepath.Separator), errShallowRepository), logs.String())
+
+	_, err := readGitInfo(filepath.Join(dir, "fake"), []string{"a.yaml"})
+	assert.NotNil(t, err)
+}
+
+func TestCommandWithoutGitInfo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	gitCommit(t, dir, "rick", "", "init", "-q")
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a")
+	gitCommit(t, dir, "rick", "2022-01-02T10:00:00Z", "add", ".")
+	gitCommit(t, dir, "rick", "2022-01-02T10:00:00Z", "commit", "-q", "-m", "add a")
+	tpl := filepath.Join(dir, "README.tpl")
+	writeFile(t, tpl, "{{range .}}{{.name}}:{{.gitCommitCount}};{{end}}")
+
+	cmd := newRootCommand()
+	buf := bytes.NewBuffer([]byte{})
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "items", "*.yaml"), "--include-header=false", "--git-info=false"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "a:;", buf.String())
+}
@@ -3,15 +3,23 @@ module github.com/linuxsuren/yaml-readme
 go 1.23
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/Masterminds/sprig v2.22.0+incompatible
+	github.com/bmatcuk/doublestar/v4 v4.8.1
+	github.com/expr-lang/expr v1.16.9
 	github.com/gofri/go-github-ratelimit v1.1.0
 	github.com/google/go-github v17.0.0+incompatible
 	github.com/h2non/gock v1.0.9
 	github.com/mmcdole/gofeed v1.3.0
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/spf13/cobra v1.4.0
+	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.8.1
+	github.com/yuin/goldmark v1.7.8
 	golang.org/x/oauth2 v0.16.0
+	golang.org/x/text v0.14.0
 	gopkg.in/yaml.v2 v2.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -33,12 +41,8 @@ require (
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/nbio/st v0.0.0-20140626010706-e9e8d9816f32 // indirect
-	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
 	golang.org/x/crypto v0.18.0 // indirect
 	golang.org/x/net v0.20.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
 	google.golang.org/appengine v1.6.7 // indirect
 	google.golang.org/protobuf v1.31.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
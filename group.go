@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// group is a group of items, the nested groups are there when grouping by multiple fields
+type group struct {
+	Key    string
+	Items  []map[string]interface{}
+	Groups []*group
+
+	// value is the original value of the key, it's used to sort the groups
+	value interface{}
+}
+
+// groupOrders are the orders of the groups, the prefix '!' means descending
+var groupOrders = map[string]bool{"key": true, "!key": true, "count": true, "!count": true}
+
+// parseGroupFields parses the fields separated by commas, for example: category,subcategory
+func parseGroupFields(groupBy string) (fields []string) {
+	for _, field := range strings.Split(groupBy, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return
+}
+
+// groupItems groups the items by the fields level by level, the item which has a list value is in each group of the
+// elements. The groups are in the order of the keys.
+func groupItems(items []map[string]interface{}, fields []string) (groups []*group) {
+	if len(fields) == 0 {
+		return
+	}
+
+	index := make(map[string]*group)
+	for _, item := range items {
+		val, _ := lookupField(item, fields[0])
+		for _, keyVal := range groupValues(val) {
+			key := fmt.Sprint(keyVal)
+			g, ok := index[key]
+			if !ok {
+				g = &group{Key: key, value: keyVal}
+				index[key] = g
+				groups = append(groups, g)
+			}
+			g.Items = append(g.Items, item)
+		}
+	}
+
+	sortGroups(groups, "key")
+	for _, g := range groups {
+		g.Groups = groupItems(g.Items, fields[1:])
+	}
+	return
+}
+
+// groupValues returns the values to group an item by, the empty values are skipped
+func groupValues(val interface{}) (values []interface{}) {
+	switch v := val.(type) {
+	case nil:
+	case string:
+		if v != "" {
+			values = []interface{}{v}
+		}
+	case int, int64, uint64, float32, float64, bool:
+		values = []interface{}{v}
+	case []interface{}:
+		for _, element := range v {
+			if _, isList := element.([]interface{}); !isList {
+				values = append(values, groupValues(element)...)
+			}
+		}
+	}
+	return
+}
+
+// sortGroups sorts the groups and the nested ones by the keys or the number of items,
+// the groups of the same number of items are sorted ascending by the keys
+func sortGroups(groups []*group, order string) {
+	descending := strings.HasPrefix(order, "!")
+	byCount := strings.TrimPrefix(order, "!") == "count"
+	sort.SliceStable(groups, func(i, j int) bool {
+		result := 0
+		if byCount {
+			result = len(groups[i].Items) - len(groups[j].Items)
+		}
+		if descending {
+			result = -result
+		}
+		if result == 0 {
+			result = compareValues(toSortValue(groups[i].value), toSortValue(groups[j].value), strings.Compare)
+			if descending && !byCount {
+				result = -result
+			}
+		}
+		return result < 0
+	})
+	for _, g := range groups {
+		sortGroups(g.Groups, order)
+	}
+}
+
+// groupsToMap turns the groups into maps which could be ranged by the keys in the template,
+// the last level is the items of each group
+func groupsToMap(groups []*group, levels int) interface{} {
+	if levels <= 1 {
+		groupData := make(map[string][]map[string]interface{}, len(groups))
+		for _, g := range groups {
+			groupData[g.Key] = g.Items
+		}
+		return groupData
+	}
+
+	groupData := make(map[string]interface{}, len(groups))
+	for _, g := range groups {
+		groupData[g.Key] = groupsToMap(g.Groups, levels-1)
+	}
+	return groupData
+}
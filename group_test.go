@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_groupItems(t *testing.T) {
+	items := []map[string]interface{}{
+		{"name": "a", "tags": []interface{}{"cli", "go"}, "year": 2021},
+		{"name": "b", "tags": []interface{}{"go"}, "year": 9},
+		{"name": "c", "tags": "web", "year": 2021},
+		{"name": "d", "tags": []interface{}{}, "year": ""},
+		{"name": "e", "tags": []interface{}{"go", "", nil, []interface{}{"x"}}},
+	}
+
+	summary := func(groups []*group) (result []string) {
+		for _, g := range groups {
+			entry := g.Key + ":"
+			for _, item := range g.Items {
+				entry += item["name"].(string)
+			}
+			for _, nested := range g.Groups {
+				entry += " " + nested.Key + "=" + string(rune('0'+len(nested.Items)))
+			}
+			result = append(result, entry)
+		}
+		return
+	}
+
+	groups := groupItems(items, []string{"tags"})
+	assert.Equal(t, []string{"cli:a", "go:abe", "web:c"}, summary(groups))
+
+	groups = groupItems(items, []string{"year", "tags"})
+	assert.Equal(t, []string{"9:b go=1", "2021:ac cli=1 go=1 web=1"}, summary(groups))
+
+	sortGroups(groups, "!key")
+	assert.Equal(t, []string{"2021:ac web=1 go=1 cli=1", "9:b go=1"}, summary(groups))
+
+	groups = groupItems(items, []string{"tags"})
+	sortGroups(groups, "!count")
+	assert.Equal(t, []string{"go:abe", "cli:a", "web:c"}, summary(groups))
+
+	assert.Nil(t, groupItems(items, nil))
+	assert.Equal(t, map[string]interface{}{
+		"2021": map[string][]map[string]interface{}{"cli": {items[0]}, "go": {items[0]}, "web": {items[2]}},
+		"9":    map[string][]map[string]interface{}{"go": {items[1]}},
+	}, groupsToMap(groupItems(items, []string{"year", "tags"}), 2))
+}
+
+func TestCommandWithGroups(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yaml"), "name: a\ncategory: cli\nsubcategory: git\ntags: [go, git]")
+	writeFile(t, filepath.Join(dir, "b.yaml"), "name: b\ncategory: cli\nsubcategory: k8s\ntags: [go]")
+	writeFile(t, filepath.Join(dir, "c.yaml"), "name: c\ncategory: web\nsubcategory: k8s\ntags: [js]")
+	tpl := filepath.Join(dir, "README.tpl")
+
+	tests := []struct {
+		name      string
+		template  string
+		args      []string
+		expect    string
+		wantError bool
+	}{{
+		name:     "list values",
+		template: "{{range $key, $val := .}}{{$key}}:{{range $val}}{{.name}}{{end}};{{end}}{{lenGroupNum}}",
+		args:     []string{"--group-by", "tags"},
+		expect:   "git:a;go:ab;js:c;3",
+	}, {
+		name:     "multiple levels",
+		template: "{{range $category, $subs := .}}{{$category}}[{{range $sub, $val := $subs}}{{$sub}}:{{len $val}};{{end}}]{{end}}",
+		args:     []string{"--group-by", "category, subcategory"},
+		expect:   "cli[git:1;k8s:1;]web[k8s:1;]",
+	}, {
+		name:     "ordered groups",
+		template: "{{range .}}{{.Key}}:{{len .Items}}[{{range .Groups}}{{.Key}}{{end}}]{{end}}",
+		args:     []string{"--group-by", "tags,category", "--group-order", "!count"},
+		expect:   "go:2[cli]git:1[cli]js:1[web]",
+	}, {
+		name:      "invalid order",
+		template:  "{{len .}}",
+		args:      []string{"--group-by", "tags", "--group-order", "size"},
+		wantError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeFile(t, tpl, tt.template)
+			cmd := newRootCommand()
+			buf := bytes.NewBuffer([]byte{})
+			cmd.SetOut(buf)
+			cmd.SetArgs(append([]string{"-t", tpl, "-p", filepath.Join(dir, "*.yaml"), "--include-header=false"}, tt.args...))
+			err := cmd.Execute()
+			if tt.wantError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expect, buf.String())
+		})
+	}
+}
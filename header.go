@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// headerPrefix is the leading mark of the template header, for example:
+// #!yaml-readme -p data/*.yaml --output README.md
+const headerPrefix = "#!yaml-readme"
+
+// headerLineReg matches the header line which should not be rendered
+var headerLineReg = regexp.MustCompile("(?m)^" + headerPrefix + " .*\n")
+
+// stripHeader removes the header line from the template content
+func stripHeader(content string) string {
+	return headerLineReg.ReplaceAllString(content, "")
+}
+
+// readHeaderLines returns the header lines of a template file, it returns nothing if the file does not exist
+func readHeaderLines(templateFile string) string {
+	data, _ := os.ReadFile(templateFile)
+	return strings.Join(headerLineReg.FindAllString(string(data), -1), "")
+}
+
+// hideHeader turns the header lines into the comments of the template, they are not rendered but the line numbers of
+// the template are kept for the errors
+func hideHeader(content string, delims delimiters) string {
+	left, right := delims.left, delims.right
+	if left == "" {
+		left = "{{"
+	}
+	if right == "" {
+		right = "}}"
+	}
+	return headerLineReg.ReplaceAllStringFunc(content, func(line string) string {
+		if strings.Contains(line, "*/") {
+			line = "\n"
+		}
+		return left + "/*" + line + "*/" + right
+	})
+}
+
+// readTemplateHeader returns the arguments which declared in the first line of a template file.
+// It returns nothing if the template file does not exist or there is no header.
+func readTemplateHeader(templateFile string) (args []string, err error) {
+	var data []byte
+	if data, err = os.ReadFile(templateFile); err != nil {
+		// the template loader takes care of the missing template
+		err = nil
+		return
+	}
+
+	line := strings.TrimSuffix(strings.SplitN(string(data), "\n", 2)[0], "\r")
+	if line == headerPrefix || strings.HasPrefix(line, headerPrefix+" ") {
+		args, err = splitArgs(strings.TrimPrefix(line, headerPrefix))
+	}
+	return
+}
+
+// applyHeaderArgs sets the flags from the header arguments, the flags which were set explicitly are not overridden
+func applyHeaderArgs(flags *pflag.FlagSet, args []string) (err error) {
+	if len(args) == 0 {
+		return
+	}
+
+	headerFlags := pflag.NewFlagSet(headerPrefix, pflag.ContinueOnError)
+	headerFlags.SetOutput(&strings.Builder{})
+	(&option{}).addFlags(headerFlags)
+	if err = headerFlags.Parse(args); err != nil {
+		return
+	}
+
+	headerFlags.Visit(func(flag *pflag.Flag) {
+		// the header belongs to the template, it makes no sense to point to another one
+		if err != nil || flag.Name == "template" {
+			return
+		}
+
+		if target := flags.Lookup(flag.Name); target != nil && !target.Changed {
+			err = setFlag(flags, flag)
+		}
+	})
+	return
+}
+
+// setFlag sets the value of a flag into the flag set, the values of a slice flag are set one by one
+func setFlag(flags *pflag.FlagSet, flag *pflag.Flag) (err error) {
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		for _, val := range slice.GetSlice() {
+			if err = flags.Set(flag.Name, val); err != nil {
+				return
+			}
+		}
+		return
+	}
+	err = flags.Set(flag.Name, flag.Value.String())
+	return
+}
+
+// splitArgs splits a command line into arguments with the shell-style quoting
+func splitArgs(line string) (args []string, err error) {
+	var (
+		arg     strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, c := range line {
+		switch {
+		case escaped:
+			// only a few characters could be escaped in the double quotes
+			if quote == '"' && !strings.ContainsRune("\"\\$`", c) {
+				arg.WriteRune('\\')
+			}
+			arg.WriteRune(c)
+			escaped = false
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(c)
+			}
+		case c == '\\':
+			escaped = true
+			inArg = true
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else {
+				arg.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(c)
+			inArg = true
+		}
+	}
+
+	switch {
+	case escaped:
+		err = errors.New("unexpected end of line after the escape character")
+	case quote != 0:
+		err = fmt.Errorf("unterminated quote %q", quote)
+	case inArg:
+		args = append(args, arg.String())
+	}
+	return
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_splitArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		wantArgs []string
+		hasError bool
+	}{{
+		name:     "simple arguments",
+		line:     " -p data/*.yaml --output README.md",
+		wantArgs: []string{"-p", "data/*.yaml", "--output", "README.md"},
+	}, {
+		name:     "quoted arguments",
+		line:     `--sort-by '!year' --group-by "kind name" -p a\ b.yaml`,
+		wantArgs: []string{"--sort-by", "!year", "--group-by", "kind name", "-p", "a b.yaml"},
+	}, {
+		name:     "escape in double quotes",
+		line:     `"a\"b" "c\d"`,
+		wantArgs: []string{`a"b`, `c\d`},
+	}, {
+		name:     "empty quoted argument",
+		line:     `--output ''`,
+		wantArgs: []string{"--output", ""},
+	}, {
+		name:     "unterminated quote",
+		line:     `--sort-by 'year`,
+		hasError: true,
+	}, {
+		name:     "unexpected end after escape",
+		line:     `--sort-by year\`,
+		hasError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := splitArgs(tt.line)
+			if tt.hasError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func Test_readTemplateHeader(t *testing.T) {
+	args, err := readTemplateHeader("function/data/README-with-metadata.tpl")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"-p", "data/financing/*.yaml", "--output", "financing.md"}, args)
+
+	args, err = readTemplateHeader("function/data/README.tpl")
+	assert.Nil(t, err)
+	assert.Empty(t, args)
+
+	args, err = readTemplateHeader("fake")
+	assert.Nil(t, err)
+	assert.Empty(t, args)
+}
+
+func TestCommandWithTemplateHeader(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a")
+	writeFile(t, filepath.Join(dir, "items", "b.yaml"), "name: b")
+	tpl := filepath.Join(dir, "README.tpl")
+	writeFile(t, tpl, "#!yaml-readme -p '"+filepath.Join(dir, "items", "*.yaml")+"' --sort-by !name --include-header=false\n"+
+		"{{- range .}}{{.name}}{{end}}")
+
+	tests := []struct {
+		name         string
+		flags        []string
+		expectOutput string
+	}{{
+		name:         "use the header arguments",
+		flags:        []string{"-t", tpl},
+		expectOutput: "ba",
+	}, {
+		name:         "explicit flags win",
+		flags:        []string{"-t", tpl, "--sort-by", "name"},
+		expectOutput: "ab",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newRootCommand()
+			buf := bytes.NewBuffer([]byte{})
+			cmd.SetOut(buf)
+			cmd.SetArgs(tt.flags)
+
+			assert.Nil(t, cmd.Execute())
+			assert.Equal(t, tt.expectOutput, buf.String())
+		})
+	}
+
+	t.Run("write to the output of the header", func(t *testing.T) {
+		output := filepath.Join(dir, "README.md")
+		writeFile(t, tpl, "#!yaml-readme -p "+filepath.Join(dir, "items", "*.yaml")+" --output "+output+"\n{{len .}}")
+
+		cmd := newRootCommand()
+		cmd.SetOut(bytes.NewBuffer([]byte{}))
+		cmd.SetArgs([]string{"-t", tpl, "--include-header=false"})
+		assert.Nil(t, cmd.Execute())
+
+		data, err := os.ReadFile(output)
+		assert.Nil(t, err)
+		assert.Equal(t, "2", string(data))
+	})
+
+	t.Run("invalid header", func(t *testing.T) {
+		writeFile(t, tpl, "#!yaml-readme --unknown\n")
+
+		cmd := newRootCommand()
+		cmd.SetOut(bytes.NewBuffer([]byte{}))
+		cmd.SetArgs([]string{"-t", tpl})
+		assert.NotNil(t, cmd.Execute())
+	})
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func Test_hideHeader(t *testing.T) {
+	content := "#!yaml-readme -p 'items/**/*.yaml'\n#!yaml-readme --sort-by name\n{{.name}}"
+	assert.Equal(t, "{{/*\n*/}}{{/*#!yaml-readme --sort-by name\n*/}}{{.name}}", hideHeader(content, delimiters{}))
+	assert.Equal(t, hideHeader(content, delimiters{}), hideHeader(hideHeader(content, delimiters{}), delimiters{}))
+	assert.Equal(t, "[[/*#!yaml-readme -t a.tpl\n*/]]text", hideHeader("#!yaml-readme -t a.tpl\ntext", delimiters{"[[", "]]"}))
+	assert.Equal(t, "text #!yaml-readme -t a.tpl\n", hideHeader("text #!yaml-readme -t a.tpl\n", delimiters{}))
+
+	output, err := renderTemplateToString(hideHeader(content, delimiters{}), map[string]string{"name": "a"}, delimiters{})
+	assert.Nil(t, err)
+	assert.Equal(t, "a", output)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// defaultsFile holds the default values of the items in the same directory and the sub-directories
+const defaultsFile = "_defaults.yaml"
+
+// defaultsLoader loads the defaults files from a directory up to the working directory, or up to the root of the file
+// system if the directory is out of the working directory
+type defaultsLoader struct {
+	root  string
+	cache map[string]map[string]interface{}
+}
+
+func newDefaultsLoader() *defaultsLoader {
+	root, _ := os.Getwd()
+	return &defaultsLoader{root: root, cache: make(map[string]map[string]interface{})}
+}
+
+// load returns the defaults of a directory, the defaults of the ancestors are merged beneath it
+func (l *defaultsLoader) load(dir string) (defaults map[string]interface{}, err error) {
+	if dir, err = filepath.Abs(dir); err != nil {
+		return
+	}
+	var ok bool
+	if defaults, ok = l.cache[dir]; ok {
+		return
+	}
+
+	var parentDefaults map[string]interface{}
+	if parent := filepath.Dir(dir); parent != dir && dir != l.root {
+		if parentDefaults, err = l.load(parent); err != nil {
+			return
+		}
+	}
+
+	var data []byte
+	file := filepath.Join(dir, defaultsFile)
+	if data, err = os.ReadFile(file); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err = nil
+			defaults = parentDefaults
+			l.cache[dir] = defaults
+		}
+		return
+	}
+
+	var ownDefaults map[string]interface{}
+	if ownDefaults, err = decodeYAMLMap(data); err != nil {
+		err = fmt.Errorf("failed to parse defaults file %q, error: %v", file, err)
+		return
+	}
+	normalizeValues(ownDefaults)
+	defaults = mergeMaps(parentDefaults, ownDefaults)
+	l.cache[dir] = defaults
+	return
+}
+
+// mergeMaps returns a new map of the base fields overridden by the fields, the nested maps are merged deeply
+func mergeMaps(base, fields map[string]interface{}) (merged map[string]interface{}) {
+	merged = make(map[string]interface{}, len(base)+len(fields))
+	for key, val := range base {
+		if nested, ok := val.(map[string]interface{}); ok {
+			val = mergeMaps(nested, nil)
+		}
+		merged[key] = val
+	}
+	for key, val := range fields {
+		baseNested, baseOK := merged[key].(map[string]interface{})
+		nested, ok := val.(map[string]interface{})
+		if baseOK && ok {
+			val = mergeMaps(baseNested, nested)
+		}
+		merged[key] = val
+	}
+	return
+}
+
+// inheritItems merges the directory defaults and the extended item beneath the fields of each item.
+// The key 'extends' is the filename of another item without the extension, such as: extends: base,
+// the one in the same directory wins. The built-in variables are not inherited.
+func inheritItems(items, defaults []map[string]interface{}) (inheritedItems []map[string]interface{}, err error) {
+	inheritedItems = make([]map[string]interface{}, len(items))
+	resolving := make([]bool, len(items))
+
+	var resolve func(i int) error
+	resolve = func(i int) (err error) {
+		if inheritedItems[i] != nil {
+			return
+		}
+		if resolving[i] {
+			return fmt.Errorf("circular extends of item %q", items[i]["fullpath"])
+		}
+		resolving[i] = true
+
+		base := defaults[i]
+		if extends, ok := items[i]["extends"]; ok {
+			var target int
+			if target, err = findExtendedItem(items, i, extends); err != nil {
+				return
+			}
+			if err = resolve(target); err != nil {
+				return
+			}
+			base = mergeMaps(base, inheritableFields(inheritedItems[target]))
+		}
+		inheritedItems[i] = mergeMaps(base, items[i])
+		return
+	}
+
+	for i := range items {
+		if err = resolve(i); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func findExtendedItem(items []map[string]interface{}, index int, extends interface{}) (target int, err error) {
+	name, ok := extends.(string)
+	if !ok || name == "" {
+		err = fmt.Errorf("invalid extends %v of item %q, it should be the filename of another item", extends, items[index]["fullpath"])
+		return
+	}
+
+	dir := filepath.Dir(fmt.Sprint(items[index]["fullpath"]))
+	var sameDir, others []int
+	for i, item := range items {
+		if i == index || item["filename"] != name {
+			continue
+		}
+		if filepath.Dir(fmt.Sprint(item["fullpath"])) == dir {
+			sameDir = append(sameDir, i)
+		} else {
+			others = append(others, i)
+		}
+	}
+
+	candidates := sameDir
+	if len(candidates) == 0 {
+		candidates = others
+	}
+	switch len(candidates) {
+	case 0:
+		err = fmt.Errorf("cannot find the item %q which is extended by %q", name, items[index]["fullpath"])
+	case 1:
+		target = candidates[0]
+	default:
+		err = fmt.Errorf("there are %d items %q which are extended by %q", len(candidates), name, items[index]["fullpath"])
+	}
+	return
+}
+
+func inheritableFields(item map[string]interface{}) (fields map[string]interface{}) {
+	fields = make(map[string]interface{}, len(item))
+	for key, val := range item {
+		if !builtinVariables[key] {
+			fields[key] = val
+		}
+	}
+	return
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_mergeMaps(t *testing.T) {
+	base := map[string]interface{}{
+		"license": "MIT", "tags": []interface{}{"a"}, "links": map[string]interface{}{"github": "a", "gitee": "a"},
+	}
+	merged := mergeMaps(base, map[string]interface{}{
+		"tags": []interface{}{"b"}, "links": map[string]interface{}{"github": "b"}, "name": "b",
+	})
+	assert.Equal(t, map[string]interface{}{
+		"license": "MIT", "tags": []interface{}{"b"}, "links": map[string]interface{}{"github": "b", "gitee": "a"}, "name": "b",
+	}, merged)
+
+	// the base is not changed
+	assert.Equal(t, "a", base["links"].(map[string]interface{})["github"])
+	assert.Equal(t, map[string]interface{}{}, mergeMaps(nil, nil))
+}
+
+func Test_readItemsWithInheritance(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, defaultsFile), "license: Apache\nfake: true")
+	writeFile(t, filepath.Join(dir, "items", defaultsFile), "license: MIT\nlinks:\n  site: https://example.com\nkind: tool")
+	writeFile(t, filepath.Join(dir, "items", "cli", defaultsFile), "kind: cli")
+	writeFile(t, filepath.Join(dir, "items", "cli", "a.yaml"), "name: a\nlinks:\n  github: a")
+	writeFile(t, filepath.Join(dir, "items", "cli", "b.yaml"), "name: b\nextends: base\nlinks:\n  gitee: b")
+	writeFile(t, filepath.Join(dir, "items", "cli", "base.yaml"), "kind: base\nstars: 10\nlinks:\n  github: base\nignore: true")
+	writeFile(t, filepath.Join(dir, "items", "web", "c.yaml"), "name: c\nextends: base")
+
+	// the defaults out of the working directory are not merged
+	wd, err := os.Getwd()
+	assert.Nil(t, err)
+	assert.Nil(t, os.Chdir(filepath.Join(dir, "items")))
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	items, err := readItems([]string{filepath.Join(dir, "items", "**", "*.yaml")}, func(file string, err error) {
+		assert.Fail(t, "unexpected error", "%s: %v", file, err)
+	})
+	assert.Nil(t, err)
+	// the ignored base is extended by others
+	assert.Equal(t, 4, len(items))
+	items, err = filterItems(items, defaultFilter)
+	assert.Nil(t, err)
+	if !assert.Equal(t, 3, len(items)) {
+		return
+	}
+
+	for _, item := range items {
+		delete(item, "fullpath")
+		delete(item, "docindex")
+	}
+	assert.Equal(t, map[string]interface{}{
+		"name": "a", "filename": "a", "parentname": "cli", "license": "MIT", "kind": "cli",
+		"links": map[string]interface{}{"github": "a", "site": "https://example.com"},
+	}, items[0])
+	assert.Equal(t, map[string]interface{}{
+		"name": "b", "filename": "b", "parentname": "cli", "license": "MIT", "kind": "base", "stars": 10, "extends": "base",
+		"links": map[string]interface{}{"github": "base", "gitee": "b", "site": "https://example.com"},
+	}, items[1])
+	assert.Equal(t, map[string]interface{}{
+		"name": "c", "filename": "c", "parentname": "web", "license": "MIT", "kind": "base", "stars": 10, "extends": "base",
+		"links": map[string]interface{}{"github": "base", "site": "https://example.com"},
+	}, items[2])
+}
+
+func Test_readItemsWithAncestorDefaults(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "items", defaultsFile), "license: MIT")
+	writeFile(t, filepath.Join(dir, "items", "sub", defaultsFile), "kind: cli")
+	writeFile(t, filepath.Join(dir, "items", "sub", "i.yaml"), "name: i")
+
+	// the ancestor defaults do not depend on the base directory of the pattern
+	for _, pattern := range []string{"items/**/*.yaml", "items/*/i.yaml", "items/sub/*.yaml"} {
+		items, err := readItems([]string{filepath.Join(dir, filepath.FromSlash(pattern))}, func(file string, err error) {
+			assert.Fail(t, "unexpected error", "%s: %v", file, err)
+		})
+		assert.Nil(t, err, pattern)
+		if assert.Equal(t, 1, len(items), pattern) {
+			assert.Equal(t, "MIT", items[0]["license"], pattern)
+			assert.Equal(t, "cli", items[0]["kind"], pattern)
+		}
+	}
+}
+
+func Test_inheritItemsErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		items     []map[string]interface{}
+		wantError string
+	}{{
+		name: "circular extends",
+		items: []map[string]interface{}{
+			{"filename": "a", "fullpath": "a.yaml", "extends": "b"},
+			{"filename": "b", "fullpath": "b.yaml", "extends": "a"},
+		},
+		wantError: `circular extends of item "a.yaml"`,
+	}, {
+		name:      "missing item",
+		items:     []map[string]interface{}{{"filename": "a", "fullpath": "a.yaml", "extends": "b"}},
+		wantError: `cannot find the item "b" which is extended by "a.yaml"`,
+	}, {
+		name: "ambiguous items",
+		items: []map[string]interface{}{
+			{"filename": "a", "fullpath": "a.yaml", "extends": "b"},
+			{"filename": "b", "fullpath": "x/b.yaml"},
+			{"filename": "b", "fullpath": "y/b.yaml"},
+		},
+		wantError: `there are 2 items "b" which are extended by "a.yaml"`,
+	}, {
+		name:      "invalid extends",
+		items:     []map[string]interface{}{{"filename": "a", "fullpath": "a.yaml", "extends": 1}},
+		wantError: `invalid extends 1 of item "a.yaml", it should be the filename of another item`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := inheritItems(tt.items, make([]map[string]interface{}, len(tt.items)))
+			assert.EqualError(t, err, tt.wantError)
+		})
+	}
+}
+
+func TestCommandWithDefaults(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, defaultsFile), "license: MIT\nlinks:\n  site: https://example.com")
+	writeFile(t, filepath.Join(dir, "a.yaml"), "name: a")
+	writeFile(t, filepath.Join(dir, "b.yaml"), "name: b\nlicense: Apache")
+	tpl := filepath.Join(dir, "README.tpl")
+	writeFile(t, tpl, "{{range .}}{{.name}}:{{.license}};{{end}}")
+
+	cmd := newRootCommand()
+	buf := bytes.NewBuffer([]byte{})
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "*.yaml"), "--include-header=false"})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "a:MIT;b:Apache;", buf.String())
+
+	cmd = newRootCommand()
+	buf = bytes.NewBuffer([]byte{})
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "*.yaml"), "--print-variables"})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "\nlicense     string   2      \"MIT\"\n")
+	assert.Contains(t, buf.String(), "\nlinks.site  string   2      \"https://example.com\"\n")
+
+	writeFile(t, filepath.Join(dir, defaultsFile), "license: [MIT")
+	cmd = newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "*.yaml")})
+	assert.NotNil(t, cmd.Execute())
+}
@@ -2,120 +2,135 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"log"
-	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"regexp"
-	"sort"
-	"strconv"
 	"strings"
 
-	"github.com/Masterminds/sprig"
-	"github.com/linuxsuren/yaml-readme/function"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v2"
+	"github.com/spf13/pflag"
 )
 
 var logger *log.Logger
 
 type option struct {
-	pattern       string
+	patterns      []string
 	templateFile  string
+	partials      string
+	layout        string
+	engine        string
+	delims        string
+	strict        bool
+	fallback      string
 	includeHeader bool
+	gitInfo       bool
 	sortBy        string
+	collation     string
 	groupBy       string
+	groupOrder    string
+	filter        string
 	output        string
+	schema        string
+	section       string
+	check         bool
 
 	printFunctions bool
 	printVariables bool
+	printFormat    string
+
+	// vars are the variables of the config job, the variables file and the flags override them
+	vars     map[string]string
+	varsFile string
+	varArgs  []string
+	// env is the allowlist of the environment variables which could be used in the template
+	env []string
+	// config and job only work for the root command
+	config string
+	job    string
 }
 
-type FileWriter struct {
-	file *os.File
+func loadItems(patterns []string) (items []map[string]interface{}, err error) {
+	// the files fail to read or parse are skipped
+	items, err = readItems(patterns, func(file string, err error) {
+		logger.Printf("failed to parse file [%s], error: %v\n", file, err)
+	})
+	return
 }
 
-func NewFileWriter(filePath string) (*FileWriter, error) {
-	file, err := os.Create(filePath)
-	if err != nil {
-		return nil, err
+// readItems reads the items from YAML, JSON, TOML, Markdown and CSV files, the directory defaults and the extended
+// item are merged beneath the fields of each item. The files fail to read or parse are passed to the handler.
+// The ignored items are kept, the default filter skips them.
+func readItems(patterns []string, onError func(file string, err error)) (items []map[string]interface{}, err error) {
+	var files []string
+	if files, err = findFiles(patterns); err != nil {
+		return
 	}
-	return &FileWriter{file: file}, nil
-}
 
-func (fw *FileWriter) Write(p []byte) (n int, err error) {
-	return fw.file.Write(p)
-}
-
-func loadMetadata(pattern, groupBy string) (items []map[string]interface{},
-	groupData map[string][]map[string]interface{}, err error) {
-	groupData = make(map[string][]map[string]interface{})
-
-	// find YAML files
-	var files []string
-	var data []byte
-	if files, err = filepath.Glob(pattern); err == nil {
-		for _, metaFile := range files {
-			if data, err = os.ReadFile(metaFile); err != nil {
-				logger.Printf("failed to read file [%s], error: %v\n", metaFile, err)
-				continue
-			}
+	var allItems, defaults []map[string]interface{}
+	loader := newDefaultsLoader()
+	for _, metaFile := range files {
+		if filepath.Base(metaFile) == defaultsFile {
+			continue
+		}
 
-			metaMap := make(map[string]interface{})
-			if err = yaml.Unmarshal(data, metaMap); err != nil {
-				logger.Printf("failed to parse file [%s] as a YAML, error: %v\n", metaFile, err)
-				continue
-			}
+		var data []byte
+		if data, err = os.ReadFile(metaFile); err != nil {
+			onError(metaFile, err)
+			continue
+		}
 
-			// skip this item if there is a 'ignore' key is true
-			if val, ok := metaMap["ignore"]; ok {
-				if ignore, ok := val.(bool); ok && ignore {
-					continue
-				}
-			}
+		var metaMaps []map[string]interface{}
+		if metaMaps, err = decodeItems(metaFile, data); err != nil {
+			onError(metaFile, err)
+			continue
+		}
 
-			filename := strings.TrimSuffix(filepath.Base(metaFile), filepath.Ext(metaFile))
-			parentname := filepath.Base(filepath.Dir(metaFile))
+		var dirDefaults map[string]interface{}
+		if dirDefaults, err = loader.load(filepath.Dir(metaFile)); err != nil {
+			return
+		}
 
+		filename := strings.TrimSuffix(filepath.Base(metaFile), filepath.Ext(metaFile))
+		parentname := filepath.Base(filepath.Dir(metaFile))
+		for docIndex, metaMap := range metaMaps {
 			metaMap["filename"] = filename
 			metaMap["parentname"] = parentname
 			metaMap["fullpath"] = metaFile
+			metaMap["docindex"] = docIndex
 
-			if val, ok := metaMap[groupBy]; ok && val != "" {
-				var strVal string
-				switch val.(type) {
-				case string:
-					strVal = val.(string)
-				case int:
-					strVal = strconv.Itoa(val.(int))
-				}
-
-				if _, ok := groupData[strVal]; ok {
-					groupData[strVal] = append(groupData[strVal], metaMap)
-				} else {
-					groupData[strVal] = []map[string]interface{}{
-						metaMap,
-					}
-				}
-			}
-
-			items = append(items, metaMap)
+			allItems = append(allItems, metaMap)
+			defaults = append(defaults, dirDefaults)
 		}
 	}
+	err = nil
+
+	// the ignored items could be extended by others, they are skipped by the default filter
+	items, err = inheritItems(allItems, defaults)
 	return
 }
 
-func sortMetadata(items []map[string]interface{}, sortByField string) {
-	descending := true
-	if strings.HasPrefix(sortByField, "!") {
-		sortByField = strings.TrimPrefix(sortByField, "!")
-		descending = false
+// metadataCache holds the items of each group of patterns, it avoids loading the same files repeatedly
+type metadataCache map[string][]map[string]interface{}
+
+// load loads the items of the patterns, the git info is added to them if gitInfo is true
+func (c metadataCache) load(patterns []string, gitInfo bool) (items []map[string]interface{}, err error) {
+	key := fmt.Sprintf("%s;%t", strings.Join(patterns, ","), gitInfo)
+	var ok bool
+	if items, ok = c[key]; !ok {
+		if items, err = loadItems(patterns); err == nil {
+			if gitInfo {
+				addGitInfo(items)
+			}
+			c[key] = items
+		}
 	}
-	sortBy(items, sortByField, descending)
+	// sorting changes the order in place, do not affect the cached items
+	items = append([]map[string]interface{}(nil), items...)
+	return
 }
 
 func loadTemplate(templateFile string, includeHeader bool) (readmeTpl string, err error) {
@@ -131,195 +146,303 @@ func loadTemplate(templateFile string, includeHeader bool) (readmeTpl string, er
 {{- end}}`
 	}
 	if includeHeader {
-		readmeTpl = fmt.Sprintf("> This file was generated by [%s](%s) via [yaml-readme](https://github.com/LinuxSuRen/yaml-readme), please don't edit it directly!\n\n",
-			filepath.Base(templateFile), filepath.Base(templateFile))
+		readmeTpl = noticeHeader(templateFile)
 	}
-	readmeTpl = readmeTpl + string(data)
-	s, err := regexp.Compile("#!yaml-readme .*\n")
-	readmeTpl = s.ReplaceAllString(readmeTpl, "")
+	readmeTpl = stripHeader(readmeTpl + string(data))
 	return
 }
 
+// loadLayout loads the layout template
+func loadLayout(layoutFile string) (layout string, err error) {
+	var data []byte
+	if data, err = os.ReadFile(layoutFile); err != nil {
+		err = fmt.Errorf("failed to load layout file from %q, error: %v", layoutFile, err)
+		return
+	}
+	layout = string(data)
+	return
+}
+
+// noticeHeader returns the notice on the top of the generated file, it points to the template
+func noticeHeader(templateFile string) string {
+	return fmt.Sprintf("> This file was generated by [%s](%s) via [yaml-readme](https://github.com/LinuxSuRen/yaml-readme), please don't edit it directly!\n\n",
+		filepath.Base(templateFile), filepath.Base(templateFile))
+}
+
 func (o *option) runE(cmd *cobra.Command, args []string) (err error) {
-	logger.Printf("use option: %+v", o)
+	if o.config != "" {
+		var cfg *config
+		if cfg, err = loadConfig(o.config, cmd.Flags().Changed("config")); err != nil {
+			return
+		}
 
-	writeTo := cmd.OutOrStdout()
-	if o.output != "" {
-		writeTo, err = NewFileWriter(o.output)
-		if err != nil {
-			logger.Fatal(err)
+		if cfg == nil && o.job != "" {
+			err = fmt.Errorf("cannot run job %q without the config file %q", o.job, o.config)
+			return
+		}
+
+		// the config file takes effect when a job is selected or there are no other explicit flags
+		// except the ones for all the jobs
+		if cfg != nil {
+			if o.job != "" || !hasExplicitFlags(cmd.Flags(), append([]string{"config"}, jobWideFlags...)...) {
+				err = o.runJobs(cmd, cfg)
+				return
+			}
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "skip the config file %q because of the explicit flags, "+
+				"select a job by --job to override it\n", o.config)
 		}
 	}
 
+	// the arguments in the template header work like the command line flags, but the explicit flags win
+	var headerArgs []string
+	if headerArgs, err = readTemplateHeader(o.templateFile); err != nil {
+		err = fmt.Errorf("failed to parse the header of template %q, error: %v", o.templateFile, err)
+		return
+	}
+	if err = applyHeaderArgs(cmd.Flags(), headerArgs); err != nil {
+		err = fmt.Errorf("invalid header of template %q, error: %v", o.templateFile, err)
+		return
+	}
+	logger.Printf("use option: %+v", o)
+
+	if (o.printFunctions || o.printVariables) && !printFormats[o.printFormat] {
+		err = fmt.Errorf("invalid print format %q, it should be one of table, json and markdown", o.printFormat)
+		return
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	var failures []error
 	if o.printFunctions {
-		printFunctions(writeTo)
+		if err = printFunctions(buf, o.printFormat); err != nil {
+			return
+		}
+	} else if o.printVariables {
+		var items []map[string]interface{}
+		if items, err = (metadataCache{}).load(o.patterns, o.gitInfo); err != nil {
+			return
+		}
+		if items, err = filterItems(items, defaultFilter); err != nil {
+			return
+		}
+		if err = printVariables(buf, items, o.printFormat); err != nil {
+			return
+		}
+	} else if failures, err = o.render(buf, metadataCache{}); err != nil {
 		return
 	}
+	if err = o.writeOutput(buf.Bytes(), cmd.OutOrStdout()); err == nil {
+		printFailures(cmd.ErrOrStderr(), o.fallback, failures)
+	}
+	return
+}
 
-	if o.printVariables {
-		printVariables(writeTo)
+// jobWideFlags are the flags which apply to all the jobs of the config file
+var jobWideFlags = []string{"check", "strict", "fallback", "include-header", "var", "vars-file", "env"}
+
+// runJobs runs the jobs of the config, the explicit flags override the jobs
+func (o *option) runJobs(cmd *cobra.Command, cfg *config) (err error) {
+	var jobs []job
+	if jobs, err = cfg.getJobs(o.job); err != nil {
 		return
 	}
 
+	// all the jobs are checked, the stale outputs are reported together
+	var staleOutputs []string
+	cache := metadataCache{}
+	for i := range jobs {
+		var opt *option
+		if opt, err = newJobOption(&jobs[i], cmd.Flags()); err != nil {
+			err = fmt.Errorf("failed to resolve job %q, error: %v", jobs[i].Name, err)
+			return
+		}
+		logger.Printf("run job %q with option: %+v", jobs[i].Name, opt)
+
+		buf := bytes.NewBuffer([]byte{})
+		var failures []error
+		if failures, err = opt.render(buf, cache); err != nil {
+			err = fmt.Errorf("failed to render job %q, error: %v", jobs[i].Name, err)
+			return
+		}
+
+		if err = opt.writeOutput(buf.Bytes(), cmd.OutOrStdout()); err != nil {
+			var stale staleOutputError
+			if !errors.As(err, &stale) {
+				return
+			}
+			staleOutputs = append(staleOutputs, string(stale))
+		}
+		printFailures(cmd.ErrOrStderr(), opt.fallback, failures)
+	}
+	err = staleOutputsError(staleOutputs)
+	return
+}
+
+// hasExplicitFlags checks if there are flags set explicitly except the ignored ones
+func hasExplicitFlags(flags *pflag.FlagSet, ignoredFlags ...string) (ok bool) {
+	flags.Visit(func(flag *pflag.Flag) {
+		for _, name := range ignoredFlags {
+			if flag.Name == name {
+				return
+			}
+		}
+		ok = true
+	})
+	return
+}
+
+// render renders the template with the metadata into the writer, the failures are the errors of the functions which
+// were replaced by the fallback
+func (o *option) render(writer io.Writer, cache metadataCache) (failures []error, err error) {
+	if o.schema != "" {
+		var violations []schemaViolation
+		if violations, err = validateFiles(o.patterns, o.schema); err == nil && len(violations) > 0 {
+			err = validationError(violations)
+		}
+		if err != nil {
+			return
+		}
+	}
+
 	// load metadata from YAML files
 	var items []map[string]interface{}
-	var groupData map[string][]map[string]interface{}
-	if items, groupData, err = loadMetadata(o.pattern, o.groupBy); err != nil {
-		err = fmt.Errorf("failed to load metadat from %q", o.pattern)
+	if items, err = cache.load(o.patterns, o.gitInfo); err != nil {
+		err = fmt.Errorf("failed to load metadat from %q, error: %v", o.patterns, err)
+		return
+	}
+	filter := o.filter
+	if filter == "" {
+		filter = defaultFilter
+	}
+	if items, err = filterItems(items, filter); err != nil {
 		return
 	}
-	groupNum := len(groupData)
-	itemNum := len(items)
 	if o.sortBy != "" {
-		sortMetadata(items, o.sortBy)
+		if err = sortMetadata(items, o.sortBy, o.collation); err != nil {
+			return
+		}
+	}
+	groupFields := parseGroupFields(o.groupBy)
+	groups := groupItems(items, groupFields)
+	if o.groupOrder != "" {
+		if !groupOrders[o.groupOrder] {
+			err = fmt.Errorf("invalid group order %q, it should be one of key, !key, count and !count", o.groupOrder)
+			return
+		}
+		sortGroups(groups, o.groupOrder)
+	}
+	groupNum := len(groups)
+	itemNum := len(items)
+
+	renderer := &templateRenderer{file: o.templateFile, groupNum: uint(groupNum), itemNum: uint(itemNum), strict: o.strict,
+		fallback: o.fallback}
+	if renderer.engine, err = resolveEngine(o.engine, o.output); err != nil {
+		return
+	}
+	if renderer.delims, err = parseDelims(o.delims); err != nil {
+		return
+	}
+	if renderer.partials, err = loadPartials(o.templateFile, o.partials); err != nil {
+		return
+	}
+	if o.layout != "" {
+		if renderer.layout, err = loadLayout(o.layout); err != nil {
+			return
+		}
 	}
 
 	// load readme template
 	var readmeTpl string
-	if readmeTpl, err = loadTemplate(o.templateFile, o.includeHeader); err != nil {
+	if readmeTpl, err = loadTemplate(o.templateFile, false); err != nil {
 		err = fmt.Errorf("failed to load template file from %q", o.templateFile)
 		return
 	}
+	// the comments take the places of the removed header lines, the errors have the line numbers of the template file
+	readmeTpl = hideHeader(readHeaderLines(o.templateFile), renderer.delims) + readmeTpl
 
 	// render it with grouped data
-	if o.groupBy != "" {
-		err = renderTemplate(readmeTpl, groupData, uint(groupNum), uint(itemNum), writeTo)
-	} else {
-		err = renderTemplate(readmeTpl, items, uint(groupNum), uint(itemNum), writeTo)
+	ctx := &templateContext{items: items, env: o.loadEnv()}
+	if renderer.vars, err = o.loadVars(); err != nil {
+		return
+	}
+	ctx.vars = renderer.vars
+
+	// the groups are maps ranged by the keys unless an order is specified
+	var data interface{} = items
+	if len(groupFields) > 0 && o.groupOrder != "" {
+		ctx.groups = groups
+	} else if len(groupFields) > 0 {
+		ctx.groups = groupsToMap(groups, len(groupFields))
+	}
+	if ctx.groups != nil {
+		data = ctx.groups
 	}
-	return
-}
 
-func renderTemplateToString(tplContent string, object interface{}) (output string, err error) {
-	buf := bytes.NewBuffer([]byte{})
-	if err = renderTemplate(tplContent, object, 0, 0, buf); err == nil {
-		output = buf.String()
+	// the notice header is for the whole file instead of a section, it's on the top of the layout if there is
+	if o.includeHeader && o.section == "" {
+		if _, err = io.WriteString(writer, noticeHeader(o.templateFile)); err != nil {
+			return
+		}
 	}
-	return
-}
 
-func renderTemplate(tplContent string, object interface{}, groupNum, itemNum uint, writer io.Writer) (err error) {
-	var tpl *template.Template
-	if tpl, err = template.New("readme").
-		Funcs(getFuncMap(tplContent, groupNum, itemNum)).
-		Funcs(sprig.FuncMap()).Parse(tplContent); err == nil {
-		err = tpl.Execute(writer, object)
+	root, release := newTemplateRoot(data, ctx)
+	defer release()
+	if err = renderer.render(readmeTpl, root, writer); err == nil {
+		failures = renderer.failures
 	}
 	return
 }
 
-func printVariables(stdout io.Writer) {
-	_, _ = stdout.Write([]byte(`filename
-parentname
-fullpath`))
+// printFailures prints a summary of the functions which failed and were replaced by the fallback
+func printFailures(writer io.Writer, fallback string, failures []error) {
+	if len(failures) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(writer, "%d function calls failed and were rendered as %q:\n", len(failures), fallback)
+	for _, failure := range failures {
+		_, _ = fmt.Fprintf(writer, "  - %v\n", failure)
+	}
 }
 
-func printFunctions(stdout io.Writer) {
-	funcMap := getFuncMap("", 0, 0)
-	var funcs []string
-	for k := range funcMap {
-		funcs = append(funcs, k)
+func renderTemplateToString(tplContent string, object interface{}, delims delimiters) (output string, err error) {
+	buf := bytes.NewBuffer([]byte{})
+	if err = (&templateRenderer{delims: delims}).render(tplContent, object, buf); err == nil {
+		output = buf.String()
 	}
-	sort.SliceStable(funcs, func(i, j int) bool {
-		return strings.Compare(funcs[i], funcs[j]) < 0
-	})
-	_, _ = stdout.Write([]byte(strings.Join(funcs, "\n")))
+	return
 }
 
 func getFuncMap(readmeTpl string, groupNum, itemNum uint) template.FuncMap {
-	return template.FuncMap{
-		"printHelp": func(cmd string) (output string) {
-			var err error
-			var data []byte
-			if data, err = exec.Command(cmd, "--help").Output(); err != nil {
-				_, _ = fmt.Fprintln(os.Stderr, "failed to run command", cmd)
-			} else {
-				output = fmt.Sprintf(`%s
-%s
-%s`, "```shell", string(data), "```")
-			}
-			return
-		},
-		"lenItemNum": func() uint {
-			return itemNum
-		},
-		"lenGroupNum": func() uint {
-			return groupNum
-		},
-		"updateDesc": func(owner, repo string) string {
-			desc := fmt.Sprintf("🧰 记录每一个与运维相关的优秀项目，⚗️ 项目内表格通过 GitHub Action 自动生成，📥 当前收录项目 %d 个。", itemNum)
-			err := function.UpdateRepoDescription(owner, repo, desc)
-			if err != nil {
-				fmt.Printf("failed to update repo description, error: %v\n", err)
-				os.Exit(1)
-			}
-			return ""
-		},
-		"printToc": func() string {
-			return generateTOC(readmeTpl)
-		},
-		"printContributors": func(owner, repo string) template.HTML {
-			return template.HTML(function.PrintContributors(owner, repo))
-		},
-		"printStarHistory": func(owner, repo string) string {
-			return printStarHistory(owner, repo)
-		},
-		"printVisitorCount": func(id string) string {
-			return fmt.Sprintf(`![Visitor Count](https://profile-counter.glitch.me/%s/count.svg)`, id)
-		},
-		"printPages": func(owner string) string {
-			return function.PrintPages(owner)
-		},
-		"getFeedLatestPost": func(feedLink string, defaultContent string) string {
-			return function.GetFeedLatestPost(feedLink, defaultContent)
-		},
-		"getFeedLatestPostPublishedDate": func(feedLink string) string {
-			return function.GetFeedLatestPostPublishedDate(feedLink)
-		},
-		"goUrlDecode": func(link string) string {
-			decodeUrl, err := url.QueryUnescape(link)
-			if err != nil {
-				return link
-			}
-			return decodeUrl
-		},
-		"render":       dataRender,
-		"gh":           function.GithubUserLink,
-		"ghs":          function.GitHubUsersLink,
-		"ghEmoji":      function.GitHubEmojiLink,
-		"link":         function.Link,
-		"linkOrEmpty":  function.LinkOrEmpty,
-		"twitterLink":  function.TwitterLink,
-		"youTubeLink":  function.YouTubeLink,
-		"gstatic":      function.GStatic,
-		"ghID":         function.GetIDFromGHLink,
-		"ghStar":       function.GetRepoStars,
-		"ghFork":       function.GetRepoForks,
-		"ghCreate":     function.GetRepoCreateAt,
-		"ghUpdate":     function.GetRepoPushAt,
-		"ghLicense":    function.GetRepoLicenses,
-		"ghCustom":     function.GetStarLicense,
-		"printGHTable": function.PrintUserAsTable,
-	}
+	return toFuncMap(builtinFunctions(functionContext{readmeTpl: readmeTpl, groupNum: groupNum, itemNum: itemNum}))
 }
 
-func sortBy(items []map[string]interface{}, sortBy string, descending bool) {
-	sort.SliceStable(items, func(i, j int) (compare bool) {
-		left, ok := items[i][sortBy].(string)
-		if !ok {
-			return false
+// lookupField returns the value of a field, the nested field is a dotted path, for example: links.github.
+// The key which contains dots wins over the nested field.
+func lookupField(item map[string]interface{}, field string) (val interface{}, ok bool) {
+	if val, ok = item[field]; ok || !strings.Contains(field, ".") {
+		return
+	}
+
+	var object interface{} = item
+	for _, key := range strings.Split(field, ".") {
+		var nested map[string]interface{}
+		if nested, ok = object.(map[string]interface{}); !ok {
+			return
 		}
-		right, ok := items[j][sortBy].(string)
-		if !ok {
-			return false
+		if object, ok = nested[key]; !ok {
+			return
 		}
+	}
+	val = object
+	return
+}
 
-		compare = strings.Compare(left, right) < 0
-		if !descending {
-			compare = !compare
-		}
-		return
-	})
+func lookupString(item map[string]interface{}, field string) (str string, ok bool) {
+	var val interface{}
+	if val, ok = lookupField(item, field); ok {
+		str, ok = val.(string)
+	}
+	return
 }
 
 func generateTOC(txt string) (toc string) {
@@ -380,23 +503,74 @@ Some functions rely on the GitHub API, in order to avoid X-RateLimit-Limit error
 	}
 	cmd.SetOut(os.Stdout)
 	flags := cmd.Flags()
-	flags.StringVarP(&opt.pattern, "pattern", "p", "items/*.yaml",
-		"The glob pattern with Golang spec to find files")
-	flags.StringVarP(&opt.templateFile, "template", "t", "README.tpl",
+	opt.addFlags(flags)
+	flags.StringVarP(&opt.config, "config", "", defaultConfigFile,
+		"The config file which declares the render jobs, it takes effect when a job is selected or there are no other flags")
+	flags.StringVarP(&opt.job, "job", "", "",
+		"The job name of the config file to run, the flags override the settings of it")
+	cmd.AddCommand(newRenderCommand(), newValidateCommand())
+	return
+}
+
+func (o *option) addFlags(flags *pflag.FlagSet) {
+	flags.VarP(newPatternsValue(&o.patterns, "items/*.yaml"), "pattern", "p",
+		"The glob patterns to find files, it could be repeated or separated by commas. "+
+			"For example: --pattern 'items/**/*.yaml,!items/drafts/**'")
+	flags.StringVarP(&o.templateFile, "template", "t", "README.tpl",
 		"The template file which should follow Golang template spec")
-	flags.BoolVarP(&opt.includeHeader, "include-header", "", true,
+	flags.StringVarP(&o.partials, "partials", "", "",
+		"The directory of the partial templates which could be used as {{template \"name\" .}}, "+
+			"the files prefixed with '_' beside the template are used by default")
+	flags.StringVarP(&o.layout, "layout", "", "",
+		"The layout template which the template fills in, the definitions of the template override its blocks "+
+			"and the rest of the template is the block \"content\"")
+	flags.StringVarP(&o.engine, "engine", "", "",
+		"The template engine, it could be text or html which escapes the values. "+
+			"It's html for the output file with extension .html, or text by default")
+	flags.StringVarP(&o.delims, "delims", "", "",
+		"The left and right delimiters of the template actions separated by a comma, for example: --delims '[[,]]'")
+	flags.BoolVarP(&o.strict, "strict", "", false,
+		"Fail on the missing keys of the items and the failures of the built-in functions, and report the failed item")
+	flags.StringVarP(&o.fallback, "fallback", "", "",
+		"The value which the failed network functions are rendered as, such as 'N/A'. "+
+			"The failures are printed as a summary instead of stopping the rendering")
+	flags.BoolVarP(&o.includeHeader, "include-header", "", true,
 		"Indicate if include a notice header on the top of the README file")
-	flags.StringVarP(&opt.sortBy, "sort-by", "", "",
-		"Sort the array data descending by which field, or sort it ascending with the prefix '!'. For example: --sort-by !year")
-	flags.StringVarP(&opt.groupBy, "group-by", "", "",
-		"Group the array data by which field")
-	flags.StringVarP(&opt.output, "output", "", "",
+	flags.BoolVarP(&o.gitInfo, "git-info", "", true,
+		"Add the variables from the git history of the item files, such as gitCreated. "+
+			"It runs git commands for each directory, disable it if the template does not use them")
+	flags.StringVarP(&o.sortBy, "sort-by", "", "",
+		"Sort the array data ascending by the fields separated by commas, or descending with the prefix '!'. "+
+			"The nested field is a dotted path. For example: --sort-by '!year,links.github'")
+	flags.StringVarP(&o.collation, "collation", "", "",
+		"The collation to compare the strings when sorting, it could be 'nocase' or a language tag. For example: --collation zh")
+	flags.StringVarP(&o.groupBy, "group-by", "", "",
+		"Group the array data by the fields separated by commas level by level, the nested field is a dotted path. "+
+			"For example: --group-by category,meta.subcategory")
+	flags.StringVarP(&o.groupOrder, "group-order", "", "",
+		"Turn the groups into a list ordered by key, !key, count or !count, each group has the fields Key, Items and Groups")
+	flags.StringVarP(&o.filter, "filter", "", "",
+		"Render the items which match the expression only. For example: --filter 'status == \"active\" && year >= 2020'")
+	flags.StringArrayVarP(&o.varArgs, "var", "", nil,
+		"The variable of the template in the form of key=value, it could be repeated. For example: --var version=v1.0.0")
+	flags.StringVarP(&o.varsFile, "vars-file", "", "",
+		"The YAML or JSON file of the variables, the flag --var overrides them")
+	flags.StringSliceVarP(&o.env, "env", "", nil,
+		"The environment variables which could be used in the template as .Env, it could be repeated or separated by commas")
+	flags.StringVarP(&o.output, "output", "", "",
 		"output target file path")
-	flags.BoolVarP(&opt.printFunctions, "print-functions", "", false,
-		"Print all the functions and exit")
-	flags.BoolVarP(&opt.printVariables, "print-variables", "", false,
-		"Print all the variables and exit")
-	return
+	flags.StringVarP(&o.schema, "schema", "", "",
+		"The schema file to validate the items before rendering, it's a subset of JSON Schema in YAML or JSON")
+	flags.StringVarP(&o.section, "section", "", "",
+		"Render into the section of the output file between the markers '<!-- yaml-readme:start name=<section> -->' and '<!-- yaml-readme:end -->'")
+	flags.BoolVarP(&o.check, "check", "", false,
+		"Compare the rendered result with the output file without writing it, print the diff and fail if they are different")
+	flags.BoolVarP(&o.printFunctions, "print-functions", "", false,
+		"Print the built-in and Sprig functions with the signatures, descriptions and examples, then exit")
+	flags.BoolVarP(&o.printVariables, "print-variables", "", false,
+		"Print the keys of the items which match the pattern with the types, counts and samples, then exit")
+	flags.StringVarP(&o.printFormat, "print-format", "", "table",
+		"The format of printing the variables or functions, it could be table, json or markdown")
 }
 
 func main() {
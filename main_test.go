@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -55,6 +56,26 @@ func Test_sortBy(t *testing.T) {
 				"name": "1",
 			}, data[0])
 		},
+	}, {
+		name: "nested values",
+		args: args{
+			items: []map[string]interface{}{{
+				"links": map[string]interface{}{"github": "b"},
+			}, {
+				"links": map[string]interface{}{"github": "a"},
+			}, {
+				"links.github": "c",
+			}},
+			sortBy: "links.github",
+		},
+		verify: func(data []map[string]interface{}, t *testing.T) {
+			assert.Equal(t, map[string]interface{}{
+				"links": map[string]interface{}{"github": "a"},
+			}, data[0])
+			assert.Equal(t, map[string]interface{}{
+				"links.github": "c",
+			}, data[2])
+		},
 	}, {
 		name: "slice values",
 		args: args{
@@ -73,7 +94,7 @@ func Test_sortBy(t *testing.T) {
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			sortBy(tt.args.items, tt.args.sortBy, true)
+			sortBy(tt.args.items, parseSortKeys(tt.args.sortBy), strings.Compare)
 			tt.verify(tt.args.items, t)
 		})
 	}
@@ -153,7 +174,7 @@ func Test_getFuncMap(t *testing.T) {
 	assert.NotNil(t, funcMap["printVisitorCount"])
 
 	buf := bytes.NewBuffer([]byte{})
-	printFunctions(buf)
+	assert.Nil(t, printFunctions(buf, "table"))
 	for k, val := range funcMap {
 		assert.Contains(t, buf.String(), k)
 		assert.NotNil(t, val)
@@ -230,6 +251,7 @@ func Test_renderTemplateToString(t *testing.T) {
 	type args struct {
 		tplContent string
 		object     interface{}
+		delims     delimiters
 	}
 	tests := []struct {
 		name       string
@@ -254,10 +276,19 @@ func Test_renderTemplateToString(t *testing.T) {
 		wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
 			return false
 		},
+	}, {
+		name: "custom delimiters",
+		args: args{
+			tplContent: `{{ .Values.name }}: [[ .name | upper ]]`,
+			object:     map[string]interface{}{"name": "helm"},
+			delims:     delimiters{left: "[[", right: "]]"},
+		},
+		wantOutput: "{{ .Values.name }}: HELM",
+		wantErr:    assert.NoError,
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotOutput, err := renderTemplateToString(tt.args.tplContent, tt.args.object)
+			gotOutput, err := renderTemplateToString(tt.args.tplContent, tt.args.object, tt.args.delims)
 			if !tt.wantErr(t, err, fmt.Sprintf("renderTemplateToString(%v, %v)", tt.args.tplContent, tt.args.object)) {
 				return
 			}
@@ -268,7 +299,8 @@ func Test_renderTemplateToString(t *testing.T) {
 
 func Test_newRootCommand(t *testing.T) {
 	cmd := newRootCommand()
-	flags := []string{"pattern", "template", "include-header", "sort-by", "group-by", "print-functions", "print-variables"}
+	flags := []string{"pattern", "template", "partials", "layout", "engine", "delims", "strict", "fallback", "include-header", "git-info", "sort-by", "collation", "group-by", "group-order", "filter", "var", "vars-file", "env", "print-functions", "print-variables",
+		"config", "job"}
 	for _, flag := range flags {
 		assert.NotNil(t, cmd.Flag(flag))
 	}
@@ -281,33 +313,14 @@ func TestCommand(t *testing.T) {
 		hasError     bool
 		expectOutput string
 	}{{
-		name:     "print variables",
-		flags:    []string{"--print-variables"},
-		hasError: false,
-		expectOutput: `filename
-parentname
-fullpath`,
+		name:         "print variables",
+		flags:        []string{"--print-variables"},
+		hasError:     false,
+		expectOutput: "NAME  TYPE  COUNT  SAMPLE\n",
 	}, {
-		name:     "print functions",
-		flags:    []string{"--print-functions"},
-		hasError: false,
-		expectOutput: `gh
-ghEmoji
-ghID
-ghs
-gstatic
-link
-linkOrEmpty
-printContributors
-printGHTable
-printHelp
-printPages
-printStarHistory
-printToc
-printVisitorCount
-render
-twitterLink
-youTubeLink`,
+		name:     "invalid print format",
+		flags:    []string{"--print-variables", "--print-format", "yaml"},
+		hasError: true,
 	}, {
 		name:     "normal case",
 		flags:    []string{"--template", "function/data/README.tpl", "--pattern", "function/data/*.yaml", "--sort-by", "zh"},
@@ -414,7 +427,8 @@ func Test_sortMetadata(t *testing.T) {
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			sortMetadata(tt.args.items, tt.args.sortByField)
+			assert.Nil(t, sortMetadata(tt.args.items, tt.args.sortByField, ""))
+			tt.verify(t, tt.args.items)
 		})
 	}
 }
@@ -498,17 +512,17 @@ func Test_loadTemplate(t *testing.T) {
 	}
 }
 
-func Test_loadMetadata(t *testing.T) {
+func Test_readItems(t *testing.T) {
 	type args struct {
 		pattern string
 		groupBy string
 	}
 	tests := []struct {
-		name          string
-		args          args
-		wantItems     []map[string]interface{}
-		wantGroupData map[string][]map[string]interface{}
-		wantErr       assert.ErrorAssertionFunc
+		name       string
+		args       args
+		wantItems  []map[string]interface{}
+		wantGroups []*group
+		wantErr    assert.ErrorAssertionFunc
 	}{{
 		name: "normal case",
 		args: args{
@@ -516,18 +530,19 @@ func Test_loadMetadata(t *testing.T) {
 			groupBy: "year",
 		},
 		wantItems: []map[string]interface{}{{
-			"en": "en", "filename": "item-2022", "fullpath": "function/data/item-2022.yaml", "jd": "jd", "parentname": "data", "zh": "zh", "year": 2022,
+			"en": "en", "filename": "item-2022", "fullpath": "function/data/item-2022.yaml", "jd": "jd", "parentname": "data", "zh": "zh", "year": 2022, "docindex": 0,
 		}, {
-			"en": "en", "filename": "item", "fullpath": "function/data/item.yaml", "jd": "jd", "parentname": "data", "zh": "zh", "year": 2021,
+			"en": "en", "filename": "item", "fullpath": "function/data/item.yaml", "jd": "jd", "parentname": "data", "zh": "zh", "year": 2021, "docindex": 0,
 		}},
-		wantGroupData: map[string][]map[string]interface{}{
-			"2021": {{
-				"en": "en", "filename": "item", "fullpath": "function/data/item.yaml", "jd": "jd", "parentname": "data", "zh": "zh", "year": 2021,
+		wantGroups: []*group{{
+			Key: "2021", value: 2021, Items: []map[string]interface{}{{
+				"en": "en", "filename": "item", "fullpath": "function/data/item.yaml", "jd": "jd", "parentname": "data", "zh": "zh", "year": 2021, "docindex": 0,
 			}},
-			"2022": {{
-				"en": "en", "filename": "item-2022", "fullpath": "function/data/item-2022.yaml", "jd": "jd", "parentname": "data", "zh": "zh", "year": 2022,
+		}, {
+			Key: "2022", value: 2022, Items: []map[string]interface{}{{
+				"en": "en", "filename": "item-2022", "fullpath": "function/data/item-2022.yaml", "jd": "jd", "parentname": "data", "zh": "zh", "year": 2022, "docindex": 0,
 			}},
-		},
+		}},
 		wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
 			assert.Nil(t, err)
 			return true
@@ -535,30 +550,78 @@ func Test_loadMetadata(t *testing.T) {
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotItems, gotGroupData, err := loadMetadata(tt.args.pattern, tt.args.groupBy)
-			if !tt.wantErr(t, err, fmt.Sprintf("loadMetadata(%v, %v)", tt.args.pattern, tt.args.groupBy)) {
+			gotItems, err := readItems(splitPatterns(tt.args.pattern), func(file string, err error) {
+				t.Errorf("failed to parse file %q, error: %v", file, err)
+			})
+			if err == nil {
+				gotItems, err = filterItems(gotItems, defaultFilter)
+			}
+			if !tt.wantErr(t, err, fmt.Sprintf("readItems(%v)", tt.args.pattern)) {
 				return
 			}
-			assert.Equalf(t, tt.wantItems, gotItems, "loadMetadata(%v, %v)", tt.args.pattern, tt.args.groupBy)
-			assert.Equalf(t, tt.wantGroupData, gotGroupData, "loadMetadata(%v, %v)", tt.args.pattern, tt.args.groupBy)
+			assert.Equalf(t, tt.wantItems, gotItems, "readItems(%v)", tt.args.pattern)
+			assert.Equalf(t, tt.wantGroups, groupItems(gotItems, parseGroupFields(tt.args.groupBy)),
+				"groupItems(%v)", tt.args.groupBy)
 		})
 	}
 }
 
 func Test_printVariables(t *testing.T) {
+	items := []map[string]interface{}{
+		{"name": "a", "filename": "a", "year": 2022, "links": map[string]interface{}{"github": "a"}},
+		{"name": "b", "filename": "b", "year": "2021", "description": strings.Repeat("b", 50)},
+	}
 	tests := []struct {
 		name       string
+		items      []map[string]interface{}
+		format     string
 		wantStdout string
 	}{{
-		name: "normal case",
-		wantStdout: `filename
-parentname
-fullpath`,
+		name:       "no items",
+		format:     "table",
+		wantStdout: "NAME  TYPE  COUNT  SAMPLE\n",
+	}, {
+		name:   "table",
+		items:  items,
+		format: "table",
+		wantStdout: `NAME          TYPE            COUNT  SAMPLE
+description   string          1      "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb...
+filename      string          2      "a"
+links         object          1      {"github":"a"}
+links.github  string          1      "a"
+name          string          2      "a"
+year          integer|string  2      2022
+`,
+	}, {
+		name:   "markdown",
+		items:  items[:1],
+		format: "markdown",
+		wantStdout: `| Name | Type | Count | Sample |
+|---|---|---|---|
+| ` + "`filename`" + ` | string | 1 | "a" |
+| ` + "`links`" + ` | object | 1 | {"github":"a"} |
+| ` + "`links.github`" + ` | string | 1 | "a" |
+| ` + "`name`" + ` | string | 1 | "a" |
+| ` + "`year`" + ` | integer | 1 | 2022 |
+`,
+	}, {
+		name:   "json",
+		items:  []map[string]interface{}{{"name": "a"}, {"name": "b"}},
+		format: "json",
+		wantStdout: `[
+  {
+    "name": "name",
+    "type": "string",
+    "count": 2,
+    "sample": "a"
+  }
+]
+`,
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			stdout := &bytes.Buffer{}
-			printVariables(stdout)
+			assert.Nil(t, printVariables(stdout, tt.items, tt.format))
 			assert.Equalf(t, tt.wantStdout, stdout.String(), "printVariables(%v)", stdout)
 		})
 	}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// writeOutput writes the content into the output file, or the stdout if there is no output file.
+// It compares the content with the output file instead of writing it in the check mode.
+// The content goes into the section of the output file if there is a section name.
+func (o *option) writeOutput(content []byte, stdout io.Writer) (err error) {
+	if o.section != "" {
+		if o.output == "" {
+			err = errors.New("the section requires an output file")
+			return
+		}
+
+		var document []byte
+		if document, err = os.ReadFile(o.output); err != nil {
+			return
+		}
+		var injected string
+		if injected, err = injectSection(string(document), o.section, string(content)); err != nil {
+			err = fmt.Errorf("failed to inject into %q, error: %v", o.output, err)
+			return
+		}
+		content = []byte(injected)
+	}
+
+	switch {
+	case o.check:
+		var stale staleOutputError
+		if err = checkOutput(o.output, content, stdout); errors.As(err, &stale) && o.section != "" {
+			err = staleOutputError(fmt.Sprintf("the section %q of %s", o.section, o.output))
+		}
+	case o.output == "":
+		_, err = stdout.Write(content)
+	default:
+		err = os.WriteFile(o.output, content, 0644)
+	}
+	return
+}
+
+// checkOutput prints the unified diff and returns an error if the output file is stale
+func checkOutput(output string, content []byte, stdout io.Writer) (err error) {
+	if output == "" {
+		err = errors.New("the check mode requires an output file")
+		return
+	}
+
+	var existing []byte
+	if existing, err = os.ReadFile(output); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return
+	}
+
+	err = nil
+	if bytes.Equal(existing, content) {
+		return
+	}
+
+	var diff string
+	if diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(existing)),
+		B:        splitLines(string(content)),
+		FromFile: output,
+		ToFile:   output + " (rendered)",
+		Context:  3,
+	}); err == nil {
+		_, _ = fmt.Fprint(stdout, diff)
+		err = staleOutputError(output)
+	}
+	return
+}
+
+// staleOutputError means the output file is different from the rendered result in the check mode
+type staleOutputError string
+
+func (e staleOutputError) Error() string {
+	return fmt.Sprintf("%s is out of date, please render it again", string(e))
+}
+
+// staleOutputsError combines the stale outputs of several renderings, it's nil if there are none
+func staleOutputsError(outputs []string) error {
+	switch len(outputs) {
+	case 0:
+		return nil
+	case 1:
+		return staleOutputError(outputs[0])
+	}
+	return fmt.Errorf("%d outputs are out of date, please render them again: %s", len(outputs), strings.Join(outputs, ", "))
+}
+
+// splitLines splits a text into lines which end with the line break
+func splitLines(text string) (lines []string) {
+	if text == "" {
+		return
+	}
+
+	lines = strings.SplitAfter(text, "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	} else {
+		lines[last] += "\n"
+	}
+	return
+}
+
+var (
+	sectionStartReg = regexp.MustCompile(`<!--\s*yaml-readme:start\s+name="?([^"\s]+)"?\s*-->`)
+	sectionEndReg   = regexp.MustCompile(`<!--\s*yaml-readme:end\s*-->`)
+)
+
+// injectSection replaces the content between the start and end markers of a section, for example:
+// <!-- yaml-readme:start name=tools -->
+// the content
+// <!-- yaml-readme:end -->
+func injectSection(document, name, content string) (result string, err error) {
+	start := -1
+	for _, match := range sectionStartReg.FindAllStringSubmatchIndex(document, -1) {
+		if document[match[2]:match[3]] != name {
+			continue
+		}
+		if start != -1 {
+			err = fmt.Errorf("duplicated section %q", name)
+			return
+		}
+		start = match[1]
+	}
+	if start == -1 {
+		err = fmt.Errorf("cannot find the start marker of section %q", name)
+		return
+	}
+
+	end := sectionEndReg.FindStringIndex(document[start:])
+	if end == nil {
+		err = fmt.Errorf("cannot find the end marker of section %q", name)
+		return
+	}
+	if next := sectionStartReg.FindStringIndex(document[start:]); next != nil && next[0] < end[0] {
+		err = fmt.Errorf("section %q is not closed before the next section", name)
+		return
+	}
+
+	result = document[:start] + "\n" + strings.Trim(content, "\n") + "\n" + document[start+end[0]:]
+	return
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_checkOutput(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "README.md")
+	writeFile(t, output, "a\nb\n")
+
+	tests := []struct {
+		name       string
+		output     string
+		content    string
+		hasError   bool
+		expectDiff string
+	}{{
+		name:    "up to date",
+		output:  output,
+		content: "a\nb\n",
+	}, {
+		name:     "stale",
+		output:   output,
+		content:  "a\nc\n",
+		hasError: true,
+		expectDiff: `--- ` + output + `
++++ ` + output + ` (rendered)
+@@ -1,2 +1,2 @@
+ a
+-b
++c
+`,
+	}, {
+		name:     "not exist",
+		output:   filepath.Join(dir, "fake.md"),
+		content:  "a\n",
+		hasError: true,
+		expectDiff: `--- ` + filepath.Join(dir, "fake.md") + `
++++ ` + filepath.Join(dir, "fake.md") + ` (rendered)
+@@ -0,0 +1 @@
++a
+`,
+	}, {
+		name:     "without output",
+		hasError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.NewBuffer([]byte{})
+			err := checkOutput(tt.output, []byte(tt.content), buf)
+			if tt.hasError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.expectDiff, buf.String())
+		})
+	}
+
+	data, err := os.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Equal(t, "a\nb\n", string(data), "the check mode should not write the output file")
+}
+
+func TestCommandWithCheck(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "README.md")
+	flags := []string{"-t", "function/data/README.tpl", "-p", "function/data/*.yaml", "--output", output}
+
+	cmd := newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs(flags)
+	assert.Nil(t, cmd.Execute())
+
+	cmd = newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs(append(flags, "--check"))
+	assert.Nil(t, cmd.Execute())
+
+	cmd = newRootCommand()
+	buf := bytes.NewBuffer([]byte{})
+	cmd.SetOut(buf)
+	cmd.SetArgs(append(flags, "--check", "--include-header=false"))
+	assert.NotNil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), "-> This file was generated by")
+}
+
+func Test_injectSection(t *testing.T) {
+	tests := []struct {
+		name       string
+		document   string
+		section    string
+		content    string
+		wantResult string
+		hasError   bool
+	}{{
+		name: "replace the section",
+		document: `# Title
+<!-- yaml-readme:start name=tools -->
+old
+<!-- yaml-readme:end -->
+footer`,
+		section: "tools",
+		content: "new\n",
+		wantResult: `# Title
+<!-- yaml-readme:start name=tools -->
+new
+<!-- yaml-readme:end -->
+footer`,
+	}, {
+		name: "multiple sections",
+		document: `<!-- yaml-readme:start name="books" -->
+<!-- yaml-readme:end -->
+prose
+<!--yaml-readme:start name=tools-->
+<!--yaml-readme:end-->`,
+		section: "tools",
+		content: "new",
+		wantResult: `<!-- yaml-readme:start name="books" -->
+<!-- yaml-readme:end -->
+prose
+<!--yaml-readme:start name=tools-->
+new
+<!--yaml-readme:end-->`,
+	}, {
+		name:     "no start marker",
+		document: "<!-- yaml-readme:end -->",
+		section:  "tools",
+		hasError: true,
+	}, {
+		name:     "no end marker",
+		document: "<!-- yaml-readme:start name=tools -->",
+		section:  "tools",
+		hasError: true,
+	}, {
+		name: "duplicated section",
+		document: `<!-- yaml-readme:start name=tools -->
+<!-- yaml-readme:end -->
+<!-- yaml-readme:start name=tools -->
+<!-- yaml-readme:end -->`,
+		section:  "tools",
+		hasError: true,
+	}, {
+		name: "not closed",
+		document: `<!-- yaml-readme:start name=tools -->
+<!-- yaml-readme:start name=books -->
+<!-- yaml-readme:end -->`,
+		section:  "tools",
+		hasError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := injectSection(tt.document, tt.section, tt.content)
+			if tt.hasError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantResult, result)
+		})
+	}
+}
+
+func TestCommandWithSection(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "README.md")
+	document := `# Hand-written title
+<!-- yaml-readme:start name=table -->
+<!-- yaml-readme:end -->
+Hand-written footer
+<!-- yaml-readme:start name=count -->
+<!-- yaml-readme:end -->
+`
+	writeFile(t, output, document)
+	writeFile(t, filepath.Join(dir, "count.tpl"), "#!yaml-readme -p function/data/*.yaml --output "+output+
+		" --section count\nCount: {{len .}}")
+
+	cmd := newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"-t", "function/data/README.tpl", "-p", "function/data/*.yaml", "--output", output, "--section", "table"})
+	assert.Nil(t, cmd.Execute())
+
+	cmd = newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"-t", filepath.Join(dir, "count.tpl")})
+	assert.Nil(t, cmd.Execute())
+
+	data, err := os.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Equal(t, `# Hand-written title
+<!-- yaml-readme:start name=table -->
+|中文名称|英文名称|JD|
+|---|---|---|
+|zh|en|jd|
+|zh|en|jd|
+<!-- yaml-readme:end -->
+Hand-written footer
+<!-- yaml-readme:start name=count -->
+Count: 2
+<!-- yaml-readme:end -->
+`, string(data))
+
+	cmd = newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"-t", filepath.Join(dir, "count.tpl"), "--check"})
+	assert.Nil(t, cmd.Execute())
+
+	cmd = newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"-t", filepath.Join(dir, "count.tpl"), "--section", "fake"})
+	assert.NotNil(t, cmd.Execute())
+}
+
+func TestCommandWithCheckJobs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a")
+	tpl := filepath.Join(dir, "README.tpl")
+	writeFile(t, tpl, "{{range .}}{{.name}}{{end}}")
+	readme := filepath.Join(dir, "README.md")
+	writeFile(t, readme, "<!-- yaml-readme:start name=one -->\nold\n<!-- yaml-readme:end -->\n"+
+		"<!-- yaml-readme:start name=two -->\nold\n<!-- yaml-readme:end -->\n")
+	fresh := filepath.Join(dir, "fresh.md")
+	writeFile(t, fresh, "a")
+
+	configFile := filepath.Join(dir, "config.yaml")
+	job := "- name: %s\n  pattern: %s\n  template: %s\n  output: %s\n  section: %s\n  header: false\n"
+	items := filepath.Join(dir, "items", "*.yaml")
+	writeFile(t, configFile, "jobs:\n"+fmt.Sprintf(job, "one", items, tpl, readme, "one")+
+		fmt.Sprintf(job, "fresh", items, tpl, fresh, `""`)+fmt.Sprintf(job, "two", items, tpl, readme, "two"))
+
+	// all the jobs are checked even if some of them are out of date
+	cmd := newRootCommand()
+	buf := bytes.NewBuffer([]byte{})
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"--config", configFile, "--check"})
+	err := cmd.Execute()
+	assert.EqualError(t, err, fmt.Sprintf(`2 outputs are out of date, please render them again: the section "one" of %s, `+
+		`the section "two" of %s`, readme, readme))
+	assert.Equal(t, 2, strings.Count(buf.String(), "--- "+readme+"\n"))
+}
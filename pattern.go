@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// patternsValue is a flag value of the glob patterns, it could be repeated or separated by commas
+type patternsValue struct {
+	patterns *[]string
+	changed  bool
+}
+
+func newPatternsValue(patterns *[]string, defaults ...string) *patternsValue {
+	*patterns = defaults
+	return &patternsValue{patterns: patterns}
+}
+
+// Set appends the patterns, the default patterns are replaced at the first time
+func (v *patternsValue) Set(value string) (err error) {
+	if !v.changed {
+		*v.patterns = nil
+		v.changed = true
+	}
+	*v.patterns = append(*v.patterns, splitPatterns(value)...)
+	return
+}
+
+func (v *patternsValue) String() string {
+	return strings.Join(*v.patterns, ",")
+}
+
+func (v *patternsValue) Type() string {
+	return "strings"
+}
+
+// splitPatterns splits the patterns by the commas which are not in the braces, such as: a/*.yaml,b/{c,d}.yaml
+func splitPatterns(value string) (patterns []string) {
+	var depth, start int
+	for i, c := range value {
+		switch c {
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				patterns = appendPattern(patterns, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	patterns = appendPattern(patterns, value[start:])
+	return
+}
+
+func appendPattern(patterns []string, pattern string) []string {
+	if pattern = strings.TrimSpace(pattern); pattern != "" {
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+// findFiles returns the sorted files which match the patterns. It supports the doublestar, such as: items/**/*.yaml,
+// and the patterns which have the prefix '!' exclude the matched files.
+func findFiles(patterns []string) (files []string, err error) {
+	var includes, excludes []string
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			excludes = append(excludes, filepath.Clean(strings.TrimPrefix(pattern, "!")))
+		} else {
+			includes = append(includes, pattern)
+		}
+	}
+
+	for _, pattern := range excludes {
+		if !doublestar.ValidatePathPattern(pattern) {
+			err = fmt.Errorf("invalid pattern %q", "!"+pattern)
+			return
+		}
+	}
+
+	found := make(map[string]bool)
+	for _, pattern := range includes {
+		var matches []string
+		if matches, err = doublestar.FilepathGlob(pattern, doublestar.WithFilesOnly()); err != nil {
+			err = fmt.Errorf("invalid pattern %q, error: %v", pattern, err)
+			return
+		}
+
+		for _, file := range matches {
+			if !found[file] && !isExcluded(file, excludes) {
+				found[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	sort.Strings(files)
+	return
+}
+
+func isExcluded(file string, excludes []string) bool {
+	file = filepath.Clean(file)
+	for _, pattern := range excludes {
+		if ok, _ := doublestar.PathMatch(pattern, file); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_splitPatterns(t *testing.T) {
+	assert.Equal(t, []string{"a/*.yaml", "b/{c,d}.yaml", "!e/**"}, splitPatterns("a/*.yaml, b/{c,d}.yaml,,!e/**"))
+	assert.Empty(t, splitPatterns(""))
+}
+
+func Test_patternsValue(t *testing.T) {
+	var patterns []string
+	value := newPatternsValue(&patterns, "items/*.yaml")
+	assert.Equal(t, "items/*.yaml", value.String())
+	assert.Equal(t, "strings", value.Type())
+
+	assert.Nil(t, value.Set("a/*.yaml,b/*.yaml"))
+	assert.Nil(t, value.Set("!b/c.yaml"))
+	assert.Equal(t, []string{"a/*.yaml", "b/*.yaml", "!b/c.yaml"}, patterns)
+}
+
+func Test_findFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"a.yaml", "tools/b.yaml", "tools/cli/c.yaml", "drafts/d.yaml", "tools/e.json"} {
+		writeFile(t, filepath.Join(dir, "items", file), "name: fake")
+	}
+	items := filepath.Join(dir, "items")
+
+	tests := []struct {
+		name      string
+		patterns  []string
+		wantFiles []string
+		hasError  bool
+	}{{
+		name:      "doublestar",
+		patterns:  []string{filepath.Join(items, "**", "*.yaml")},
+		wantFiles: []string{"a.yaml", "drafts/d.yaml", "tools/b.yaml", "tools/cli/c.yaml"},
+	}, {
+		name:      "exclusion",
+		patterns:  []string{filepath.Join(items, "**", "*.yaml"), "!" + filepath.Join(items, "drafts", "**")},
+		wantFiles: []string{"a.yaml", "tools/b.yaml", "tools/cli/c.yaml"},
+	}, {
+		name:      "multiple patterns without duplicated files",
+		patterns:  []string{filepath.Join(items, "tools", "**"), filepath.Join(items, "*.yaml"), filepath.Join(items, "tools", "*.yaml")},
+		wantFiles: []string{"a.yaml", "tools/b.yaml", "tools/cli/c.yaml", "tools/e.json"},
+	}, {
+		name:      "braces",
+		patterns:  []string{filepath.Join(items, "{a,drafts/d}.yaml")},
+		wantFiles: []string{"a.yaml", "drafts/d.yaml"},
+	}, {
+		name:     "invalid pattern",
+		patterns: []string{filepath.Join(items, "[.yaml")},
+		hasError: true,
+	}, {
+		name:     "invalid exclusion",
+		patterns: []string{filepath.Join(items, "*.yaml"), "!" + filepath.Join(items, "[")},
+		hasError: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := findFiles(tt.patterns)
+			if tt.hasError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+
+			var wantFiles []string
+			for _, file := range tt.wantFiles {
+				wantFiles = append(wantFiles, filepath.Join(items, filepath.FromSlash(file)))
+			}
+			assert.Equal(t, wantFiles, files)
+		})
+	}
+}
+
+func TestCommandWithPatterns(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a")
+	writeFile(t, filepath.Join(dir, "items", "tools", "b.yaml"), "name: b")
+	writeFile(t, filepath.Join(dir, "items", "drafts", "c.yaml"), "name: c")
+	writeFile(t, filepath.Join(dir, "items", "drafts", "d.yaml"), "name: d")
+	tpl := filepath.Join(dir, "README.tpl")
+	writeFile(t, tpl, "{{- range .}}{{.name}}{{end}}")
+
+	cmd := newRootCommand()
+	buf := bytes.NewBuffer([]byte{})
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"-t", tpl, "--include-header=false",
+		"-p", filepath.Join(dir, "items", "**", "*.yaml") + ",!" + filepath.Join(dir, "items", "drafts", "**"),
+		"-p", filepath.Join(dir, "items", "tools", "*.yaml")})
+	assert.Nil(t, cmd.Execute())
+	assert.Equal(t, "ab", buf.String())
+}
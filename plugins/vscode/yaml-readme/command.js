@@ -11,6 +11,7 @@ function generateCommand(metadata, wf, filename) {
 			commands.push("-p", wf + "/" + items[++i])
 		} else if (item == "--output") {
 			output = wf + "/" + items[++i]
+			commands.push("--output", output)
 		} else if (item == "--group-by") {
 			commands.push("--group-by", items[++i])
 		} else if (item == "--sort-by") {
@@ -17,6 +17,7 @@ var require_command = __commonJS({
           commands.push("-p", wf + "/" + items[++i]);
         } else if (item == "--output") {
           output = wf + "/" + items[++i];
+          commands.push("--output", output);
         } else if (item == "--group-by") {
           commands.push("--group-by", items[++i]);
         } else if (item == "--sort-by") {
@@ -43342,7 +43343,7 @@ function activate(context) {
       let metadata = getFirstLine(filename);
       if (metadata.startsWith("#!yaml-readme")) {
         let command = cmd.generateCommand(metadata, wf, filename);
-        cp.exec(`${command[0]} > ${command[1]}`, (err) => {
+        cp.exec(command[0], { cwd: wf }, (err) => {
           if (err) {
             console.log("error: " + err);
           }
@@ -52,7 +52,8 @@ function activate(context) {
 				let command = cmd.generateCommand(metadata, wf, filename)
 
 				// vscode.window.showInformationMessage(`yaml-readme -p "${pattern}" -t "${filename}" > ${output}`)
-				cp.exec(`${command[0]} > ${command[1]}`, (err) => {
+				// the relative paths in the header are resolved against the workspace, the same as the command line
+				cp.exec(command[0], { cwd: wf }, (err) => {
 					if (err) {
 						console.log('error: ' + err);
 					}
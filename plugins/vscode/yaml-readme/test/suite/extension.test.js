@@ -6,5 +6,5 @@ const assert = require('assert');
 const myExtension = require('../../command');
 
 let cmd = myExtension.generateCommand("#!yaml-readme -p data/*.yaml --output README.md --group-by kind --sort-by kind","wf","filename")
-assert.equal(cmd[0], "yaml-readme -t filename -p wf/data/*.yaml --group-by kind --sort-by kind")
+assert.equal(cmd[0], "yaml-readme -t filename -p wf/data/*.yaml --output wf/README.md --group-by kind --sort-by kind")
 assert.equal(cmd[1], "wf/README.md")
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// printFormats are the formats to print the variables and the functions
+var printFormats = map[string]bool{"table": true, "json": true, "markdown": true}
+
+// maxSampleLength is the max length of a sample value in the table and markdown formats
+const maxSampleLength = 40
+
+// variableInfo describes a key of the items
+type variableInfo struct {
+	Name   string      `json:"name"`
+	Type   string      `json:"type"`
+	Count  int         `json:"count"`
+	Sample interface{} `json:"sample"`
+}
+
+// collectVariables returns the keys of the items with the inferred types, the number of items which have them and a
+// sample value. The nested keys are dotted paths.
+func collectVariables(items []map[string]interface{}) (variables []*variableInfo) {
+	index := make(map[string]*variableInfo)
+	types := make(map[string]map[string]bool)
+	for _, item := range items {
+		for path, val := range fieldValues(item, "") {
+			variable, ok := index[path]
+			if !ok {
+				variable = &variableInfo{Name: path}
+				index[path] = variable
+				types[path] = make(map[string]bool)
+				variables = append(variables, variable)
+			}
+			variable.Count++
+			types[path][typeOf(val)] = true
+			if variable.Sample == nil {
+				variable.Sample = val
+			}
+		}
+	}
+
+	for _, variable := range variables {
+		var names []string
+		for name := range types[variable.Name] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		variable.Type = strings.Join(names, "|")
+	}
+	sort.Slice(variables, func(i, j int) bool {
+		return variables[i].Name < variables[j].Name
+	})
+	return
+}
+
+// fieldValues returns the values of the fields by the dotted paths, including the nested maps and the fields of them
+func fieldValues(object map[string]interface{}, parent string) (values map[string]interface{}) {
+	values = make(map[string]interface{}, len(object))
+	for key, val := range object {
+		path := joinField(parent, key)
+		values[path] = val
+		if nested, ok := val.(map[string]interface{}); ok {
+			for nestedPath, nestedVal := range fieldValues(nested, path) {
+				values[nestedPath] = nestedVal
+			}
+		}
+	}
+	return
+}
+
+// printVariables prints the keys of the items after merging the defaults and the extended items
+func printVariables(stdout io.Writer, items []map[string]interface{}, format string) (err error) {
+	variables := collectVariables(items)
+	if format == "json" {
+		err = printJSON(stdout, variables)
+		return
+	}
+
+	rows := make([][]string, len(variables))
+	for i, variable := range variables {
+		rows[i] = []string{variable.Name, variable.Type, fmt.Sprint(variable.Count), formatSample(variable.Sample)}
+	}
+	err = printTable(stdout, format, []string{"Name", "Type", "Count", "Sample"}, rows)
+	return
+}
+
+// formatSample formats a value as JSON in short
+func formatSample(val interface{}) (sample string) {
+	data, err := json.Marshal(val)
+	if err != nil {
+		data = []byte(fmt.Sprint(val))
+	}
+	if sample = string(data); len([]rune(sample)) > maxSampleLength {
+		sample = string([]rune(sample)[:maxSampleLength-3]) + "..."
+	}
+	return
+}
+
+func printJSON(stdout io.Writer, val interface{}) (err error) {
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(val)
+	return
+}
+
+// printTable prints the rows as an aligned table or a markdown table
+func printTable(stdout io.Writer, format string, header []string, rows [][]string) (err error) {
+	if format == "markdown" {
+		lines := []string{"| " + strings.Join(header, " | ") + " |", strings.Repeat("|---", len(header)) + "|"}
+		for _, row := range rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				if cell != "" && i == 0 {
+					cell = "`" + cell + "`"
+				}
+				cells[i] = strings.ReplaceAll(cell, "|", `\|`)
+			}
+			lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		}
+		_, err = fmt.Fprintln(stdout, strings.Join(lines, "\n"))
+		return
+	}
+
+	writer := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, strings.ToUpper(strings.Join(header, "\t")))
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	err = writer.Flush()
+	return
+}
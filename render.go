@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type renderOption struct {
+	option
+	all bool
+}
+
+func newRenderCommand() (cmd *cobra.Command) {
+	opt := &renderOption{}
+	cmd = &cobra.Command{
+		Use:   "render [dir]",
+		Short: "Render a template, or all the templates which have a header in a directory tree",
+		Example: `yaml-readme render -t README.tpl
+yaml-readme render --all docs`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: opt.runE,
+	}
+	flags := cmd.Flags()
+	opt.addFlags(flags)
+	flags.BoolVarP(&opt.all, "all", "", false,
+		"Render all the templates which have a header in a directory tree, the paths in headers are relative to the current directory")
+	return
+}
+
+func (o *renderOption) runE(cmd *cobra.Command, args []string) (err error) {
+	if !o.all {
+		if len(args) > 0 {
+			err = fmt.Errorf("the directory argument only works with --all")
+			return
+		}
+		err = o.option.runE(cmd, args)
+		return
+	}
+
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	var templates []string
+	if templates, err = findTemplates(dir); err != nil {
+		return
+	}
+
+	cache := metadataCache{}
+	stdout, stderr := cmd.OutOrStdout(), cmd.ErrOrStderr()
+	var failed int
+	for _, tpl := range templates {
+		var output string
+		if output, err = renderTemplateFile(tpl, cmd.Flags(), cache, stdout, stderr); err != nil {
+			failed++
+			_, _ = fmt.Fprintf(stdout, "FAILED %s: %v\n", tpl, err)
+		} else {
+			_, _ = fmt.Fprintf(stdout, "OK     %s -> %s\n", tpl, output)
+		}
+	}
+	_, _ = fmt.Fprintf(stdout, "rendered %d templates, %d failed\n", len(templates)-failed, failed)
+
+	err = nil
+	if failed > 0 {
+		err = fmt.Errorf("failed to render %d of %d templates", failed, len(templates))
+	}
+	return
+}
+
+// renderTemplateFile renders a template according to its header, the explicit flags win.
+// The summary of the functions which were replaced by the fallback is printed into the stderr.
+func renderTemplateFile(templateFile string, explicitFlags *pflag.FlagSet, cache metadataCache,
+	stdout, stderr io.Writer) (output string, err error) {
+	var opt *option
+	// each template has its own output and section
+	if opt, err = newJobOption(&job{Template: templateFile}, explicitFlags, "template", "output", "section"); err != nil {
+		return
+	}
+
+	if output = opt.output; output == "" {
+		err = fmt.Errorf("no output declared in the header")
+		return
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	var failures []error
+	if failures, err = opt.render(buf, cache); err == nil {
+		if err = opt.writeOutput(buf.Bytes(), stdout); err == nil {
+			printFailures(stderr, opt.fallback, failures)
+		}
+	}
+	return
+}
+
+// findTemplates returns all the template files which have a header in a directory tree
+func findTemplates(dir string) (templates []string, err error) {
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) (err error) {
+		if err = walkErr; err != nil {
+			return
+		}
+
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				err = filepath.SkipDir
+			}
+			return
+		}
+
+		if filepath.Ext(path) == ".tpl" {
+			// an invalid header is reported when rendering it
+			if args, headerErr := readTemplateHeader(path); headerErr != nil || len(args) > 0 {
+				templates = append(templates, path)
+			}
+		}
+		return
+	})
+	return
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderCommand(t *testing.T) {
+	dir := t.TempDir()
+	items := filepath.Join(dir, "items", "*.yaml")
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a\nkind: tool")
+	writeFile(t, filepath.Join(dir, "items", "b.yaml"), "name: b\nkind: book")
+	writeFile(t, filepath.Join(dir, "docs", "names.tpl"), "#!yaml-readme -p "+items+" --sort-by !name --output "+
+		filepath.Join(dir, "names.md")+"\n{{- range .}}{{.name}}{{end}}")
+	writeFile(t, filepath.Join(dir, "docs", "nested", "kinds.tpl"), "#!yaml-readme -p "+items+" --group-by kind --output "+
+		filepath.Join(dir, "kinds.md")+"\n{{- range $key, $val := .}}{{$key}}{{end}}")
+	writeFile(t, filepath.Join(dir, "docs", "partial.tpl"), "{{len .}}")
+	writeFile(t, filepath.Join(dir, "docs", ".hidden", "hidden.tpl"), "#!yaml-readme --output "+filepath.Join(dir, "hidden.md"))
+
+	t.Run("render all templates", func(t *testing.T) {
+		cmd := newRootCommand()
+		buf := bytes.NewBuffer([]byte{})
+		cmd.SetOut(buf)
+		cmd.SetArgs([]string{"render", "--all", "--include-header=false", dir})
+		assert.Nil(t, cmd.Execute())
+		assert.Contains(t, buf.String(), "rendered 2 templates, 0 failed")
+
+		data, err := os.ReadFile(filepath.Join(dir, "names.md"))
+		assert.Nil(t, err)
+		assert.Equal(t, "ba", string(data))
+
+		data, err = os.ReadFile(filepath.Join(dir, "kinds.md"))
+		assert.Nil(t, err)
+		assert.Equal(t, "booktool", string(data))
+
+		assert.NoFileExists(t, filepath.Join(dir, "hidden.md"))
+	})
+
+	t.Run("report the failed templates", func(t *testing.T) {
+		writeFile(t, filepath.Join(dir, "docs", "invalid.tpl"), "#!yaml-readme -p "+items+" --output "+
+			filepath.Join(dir, "invalid.md")+"\n{{.name")
+		writeFile(t, filepath.Join(dir, "docs", "no-output.tpl"), "#!yaml-readme -p "+items+"\n")
+
+		cmd := newRootCommand()
+		buf := bytes.NewBuffer([]byte{})
+		cmd.SetOut(buf)
+		cmd.SetArgs([]string{"render", "--all", dir})
+		assert.NotNil(t, cmd.Execute())
+		assert.Contains(t, buf.String(), "FAILED "+filepath.Join(dir, "docs", "invalid.tpl"))
+		assert.Contains(t, buf.String(), "FAILED "+filepath.Join(dir, "docs", "no-output.tpl")+": no output declared in the header")
+		assert.Contains(t, buf.String(), "rendered 2 templates, 2 failed")
+		assert.NoFileExists(t, filepath.Join(dir, "invalid.md"))
+	})
+
+	t.Run("render a single template", func(t *testing.T) {
+		cmd := newRootCommand()
+		buf := bytes.NewBuffer([]byte{})
+		cmd.SetOut(buf)
+		cmd.SetArgs([]string{"render", "-t", filepath.Join(dir, "docs", "partial.tpl"), "-p", items, "--include-header=false"})
+		assert.Nil(t, cmd.Execute())
+		assert.Equal(t, "2", buf.String())
+	})
+
+	t.Run("directory without --all", func(t *testing.T) {
+		cmd := newRootCommand()
+		cmd.SetOut(bytes.NewBuffer([]byte{}))
+		cmd.SetArgs([]string{"render", dir})
+		assert.NotNil(t, cmd.Execute())
+	})
+}
+
+func Test_metadataCache(t *testing.T) {
+	cache := metadataCache{}
+	items, err := cache.load([]string{"function/data/*.yaml"}, true)
+	assert.Nil(t, err)
+	// the ignored item is skipped by the filter after loading
+	assert.Equal(t, 3, len(items))
+
+	items[0], items[1] = items[1], items[0]
+	cached, err := cache.load([]string{"function/data/*.yaml"}, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "function/data/item-2022.yaml", cached[0]["fullpath"])
+}
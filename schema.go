@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// builtinVariables are added or reserved by yaml-readme, they are always allowed in the items
+var builtinVariables = map[string]bool{
+	"filename": true, "parentname": true, "fullpath": true, "docindex": true,
+	"content": true, "contentHTML": true, "excerpt": true, "ignore": true, "extends": true,
+	"gitCreated": true, "gitUpdated": true, "gitAuthor": true, "gitCommitCount": true,
+}
+
+// schema is a subset of JSON Schema to describe the items, for example:
+//
+//	required: [name]
+//	additionalProperties: false
+//	properties:
+//	  name:
+//	    type: string
+//	  tags:
+//	    type: array
+//	    items:
+//	      type: string
+type schema struct {
+	Type                 schemaTypes        `yaml:"type"`
+	Required             []string           `yaml:"required"`
+	Properties           map[string]*schema `yaml:"properties"`
+	AdditionalProperties *bool              `yaml:"additionalProperties"`
+	Items                *schema            `yaml:"items"`
+	Enum                 []interface{}      `yaml:"enum"`
+}
+
+// schemaTypes could be a type or a list of types in the schema
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalYAML(unmarshal func(interface{}) error) (err error) {
+	var single string
+	if err = unmarshal(&single); err == nil {
+		*t = schemaTypes{single}
+		return
+	}
+	err = unmarshal((*[]string)(t))
+	return
+}
+
+// schemaViolation describes a problem of an item file
+type schemaViolation struct {
+	file    string
+	line    int
+	field   string
+	message string
+}
+
+func (v schemaViolation) String() string {
+	position := v.file
+	if v.line > 0 {
+		position = fmt.Sprintf("%s:%d", v.file, v.line)
+	}
+	if v.field == "" {
+		return fmt.Sprintf("%s: %s", position, v.message)
+	}
+	return fmt.Sprintf("%s: %s: %s", position, v.field, v.message)
+}
+
+// loadSchema loads the schema from a YAML or JSON file
+func loadSchema(schemaFile string) (s *schema, err error) {
+	var data []byte
+	if data, err = os.ReadFile(schemaFile); err == nil {
+		s = &schema{}
+		if err = yaml.Unmarshal(data, s); err == nil {
+			err = s.check()
+		}
+		if err != nil {
+			err = fmt.Errorf("invalid schema %q, error: %v", schemaFile, err)
+		}
+	}
+	return
+}
+
+var schemaTypeNames = map[string]bool{
+	"string": true, "integer": true, "number": true, "boolean": true, "array": true, "object": true, "null": true,
+}
+
+// check makes sure the types of the schema are known
+func (s *schema) check() (err error) {
+	for _, typeName := range s.Type {
+		if !schemaTypeNames[typeName] {
+			return fmt.Errorf("unknown type %q", typeName)
+		}
+	}
+	for _, property := range s.Properties {
+		if property != nil {
+			if err = property.check(); err != nil {
+				return
+			}
+		}
+	}
+	if s.Items != nil {
+		err = s.Items.check()
+	}
+	return
+}
+
+// validateItem returns the violations of an item without the file and line, the built-in variables are always allowed
+func (s *schema) validateItem(item map[string]interface{}) (violations []schemaViolation) {
+	return s.validate(item, "", true)
+}
+
+func (s *schema) validate(val interface{}, field string, topLevel bool) (violations []schemaViolation) {
+	if s == nil {
+		return
+	}
+
+	if len(s.Type) > 0 && !s.matchType(val) {
+		violations = append(violations, schemaViolation{field: field,
+			message: fmt.Sprintf("should be %s instead of %s", strings.Join(s.Type, " or "), typeOf(val))})
+		return
+	}
+
+	if len(s.Enum) > 0 && !s.matchEnum(val) {
+		violations = append(violations, schemaViolation{field: field, message: fmt.Sprintf("should be one of %v", s.Enum)})
+	}
+
+	switch v := val.(type) {
+	case []interface{}:
+		for i, element := range v {
+			violations = append(violations, s.Items.validate(element, joinField(field, strconv.Itoa(i)), false)...)
+		}
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			object[fmt.Sprint(key)] = value
+		}
+		violations = append(violations, s.validateObject(object, field, topLevel)...)
+	case map[string]interface{}:
+		violations = append(violations, s.validateObject(v, field, topLevel)...)
+	}
+	return
+}
+
+func (s *schema) validateObject(object map[string]interface{}, field string, topLevel bool) (violations []schemaViolation) {
+	for _, key := range s.Required {
+		if _, ok := object[key]; !ok {
+			violations = append(violations, schemaViolation{field: joinField(field, key), message: "is required"})
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if property, ok := s.Properties[key]; ok {
+			violations = append(violations, property.validate(object[key], joinField(field, key), false)...)
+		} else if s.AdditionalProperties != nil && !*s.AdditionalProperties && !(topLevel && builtinVariables[key]) {
+			violations = append(violations, schemaViolation{field: joinField(field, key), message: "unknown key"})
+		}
+	}
+	return
+}
+
+func (s *schema) matchType(val interface{}) bool {
+	actual := typeOf(val)
+	for _, expected := range s.Type {
+		if expected == actual || (expected == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *schema) matchEnum(val interface{}) bool {
+	for _, candidate := range s.Enum {
+		if reflect.DeepEqual(candidate, val) {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON Schema type of a value
+func typeOf(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return "integer"
+	case float32:
+		return typeOf(float64(v))
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}, map[interface{}]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", val)
+}
+
+func joinField(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// sortKey is a field to sort the items by, for example: !year
+type sortKey struct {
+	field      string
+	descending bool
+}
+
+// parseSortKeys parses the fields separated by commas, the fields with prefix '!' are descending
+func parseSortKeys(sortByField string) (keys []sortKey) {
+	for _, field := range strings.Split(sortByField, ",") {
+		key := sortKey{field: strings.TrimSpace(field)}
+		if strings.HasPrefix(key.field, "!") {
+			key.field = strings.TrimSpace(strings.TrimPrefix(key.field, "!"))
+			key.descending = true
+		}
+		if key.field != "" {
+			keys = append(keys, key)
+		}
+	}
+	return
+}
+
+// sortMetadata sorts the items by the fields, for example: !year,name.
+// The collation of strings could be empty, 'nocase' or a language tag, such as: en, zh
+func sortMetadata(items []map[string]interface{}, sortByField, collation string) (err error) {
+	var compareString func(a, b string) int
+	if compareString, err = newStringComparer(collation); err == nil {
+		sortBy(items, parseSortKeys(sortByField), compareString)
+	}
+	return
+}
+
+func newStringComparer(collation string) (compare func(a, b string) int, err error) {
+	switch collation {
+	case "":
+		compare = strings.Compare
+	case "nocase":
+		compare = func(a, b string) int {
+			if result := strings.Compare(strings.ToLower(a), strings.ToLower(b)); result != 0 {
+				return result
+			}
+			return strings.Compare(a, b)
+		}
+	default:
+		var tag language.Tag
+		if tag, err = language.Parse(collation); err != nil {
+			err = fmt.Errorf("invalid collation %q, error: %v", collation, err)
+			return
+		}
+		compare = collate.New(tag).CompareString
+	}
+	return
+}
+
+// sortBy sorts the items by the keys in order, the items missing a field are always after the others
+func sortBy(items []map[string]interface{}, keys []sortKey, compareString func(a, b string) int) {
+	sort.SliceStable(items, func(i, j int) bool {
+		for _, key := range keys {
+			left, leftOK := lookupField(items[i], key.field)
+			right, rightOK := lookupField(items[j], key.field)
+			leftOK, rightOK = leftOK && left != nil, rightOK && right != nil
+
+			switch {
+			case !leftOK && !rightOK:
+				continue
+			case !leftOK:
+				return false
+			case !rightOK:
+				return true
+			}
+
+			result := compareValues(toSortValue(left), toSortValue(right), compareString)
+			if result == 0 {
+				continue
+			}
+			if key.descending {
+				result = -result
+			}
+			return result < 0
+		}
+		return false
+	})
+}
+
+// the kinds of the values in order
+const (
+	numberKind = iota
+	dateKind
+	stringKind
+	boolKind
+	otherKind
+)
+
+// sortValue is a value which could be compared, the strings in a date layout are dates
+type sortValue struct {
+	kind    int
+	number  float64
+	date    time.Time
+	str     string
+	boolean bool
+}
+
+func toSortValue(val interface{}) (v sortValue) {
+	switch val := val.(type) {
+	case int:
+		v = sortValue{kind: numberKind, number: float64(val)}
+	case int64:
+		v = sortValue{kind: numberKind, number: float64(val)}
+	case uint64:
+		v = sortValue{kind: numberKind, number: float64(val)}
+	case float32:
+		v = sortValue{kind: numberKind, number: float64(val)}
+	case float64:
+		v = sortValue{kind: numberKind, number: val}
+	case time.Time:
+		v = sortValue{kind: dateKind, date: val}
+	case string:
+		v = sortValue{kind: stringKind, str: val}
+		for _, layout := range dateLayouts {
+			if date, err := time.Parse(layout, val); err == nil {
+				v = sortValue{kind: dateKind, date: date}
+				break
+			}
+		}
+	case bool:
+		v = sortValue{kind: boolKind, boolean: val}
+	default:
+		v = sortValue{kind: otherKind}
+	}
+	return
+}
+
+// compareValues compares the values of the same kind, or the kinds of them
+func compareValues(left, right sortValue, compareString func(a, b string) int) int {
+	if left.kind != right.kind {
+		return left.kind - right.kind
+	}
+
+	switch left.kind {
+	case numberKind:
+		if left.number < right.number {
+			return -1
+		} else if left.number > right.number {
+			return 1
+		}
+	case dateKind:
+		return left.date.Compare(right.date)
+	case stringKind:
+		return compareString(left.str, right.str)
+	case boolKind:
+		if left.boolean != right.boolean {
+			if left.boolean {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
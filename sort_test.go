@@ -0,0 +1,104 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseSortKeys(t *testing.T) {
+	assert.Equal(t, []sortKey{{field: "year", descending: true}, {field: "name"}, {field: "links.github"}},
+		parseSortKeys("!year, name,,links.github"))
+	assert.Nil(t, parseSortKeys(""))
+}
+
+func Test_sortMetadataWithTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		items       []map[string]interface{}
+		sortByField string
+		collation   string
+		want        []string
+		wantError   bool
+	}{{
+		name: "numbers",
+		items: []map[string]interface{}{
+			{"name": "a", "stars": 9}, {"name": "b", "stars": 100}, {"name": "c", "stars": 10.5}, {"name": "d"},
+		},
+		sortByField: "stars",
+		want:        []string{"a", "c", "b", "d"},
+	}, {
+		name: "descending numbers with missing values",
+		items: []map[string]interface{}{
+			{"name": "a", "stars": nil}, {"name": "b", "stars": 9}, {"name": "c"}, {"name": "d", "stars": 100},
+		},
+		sortByField: "!stars",
+		want:        []string{"d", "b", "a", "c"},
+	}, {
+		name: "dates",
+		items: []map[string]interface{}{
+			{"name": "a", "date": "2022-10-01"}, {"name": "b", "date": "2022/09/30"}, {"name": "c", "date": "2021-12-31T10:00:00Z"},
+		},
+		sortByField: "date",
+		want:        []string{"c", "b", "a"},
+	}, {
+		name: "multiple keys",
+		items: []map[string]interface{}{
+			{"name": "b", "year": 2021}, {"name": "a", "year": 2021}, {"name": "c", "year": 2022}, {"name": "d"},
+		},
+		sortByField: "!year,name",
+		want:        []string{"c", "a", "b", "d"},
+	}, {
+		name: "mixed kinds",
+		items: []map[string]interface{}{
+			{"name": "a", "val": true}, {"name": "b", "val": "x"}, {"name": "c", "val": "2022-01-01"}, {"name": "d", "val": 1},
+		},
+		sortByField: "val",
+		want:        []string{"d", "c", "b", "a"},
+	}, {
+		name: "binary collation",
+		items: []map[string]interface{}{
+			{"name": "b"}, {"name": "B"}, {"name": "a"}, {"name": "é"},
+		},
+		sortByField: "name",
+		want:        []string{"B", "a", "b", "é"},
+	}, {
+		name: "case insensitive collation",
+		items: []map[string]interface{}{
+			{"name": "b"}, {"name": "a"}, {"name": "B"}, {"name": "A"},
+		},
+		sortByField: "name",
+		collation:   "nocase",
+		want:        []string{"A", "a", "B", "b"},
+	}, {
+		name: "language collation",
+		items: []map[string]interface{}{
+			{"name": "f"}, {"name": "é"}, {"name": "E"}, {"name": "a"},
+		},
+		sortByField: "name",
+		collation:   "fr",
+		want:        []string{"a", "E", "é", "f"},
+	}, {
+		name:        "invalid collation",
+		items:       []map[string]interface{}{{"name": "a"}},
+		sortByField: "name",
+		collation:   "not a language",
+		wantError:   true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := sortMetadata(tt.items, tt.sortByField, tt.collation)
+			if tt.wantError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+
+			var names []string
+			for _, item := range tt.items {
+				names = append(names, item["name"].(string))
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"text/template/parse"
+
+	"github.com/Masterminds/sprig"
+)
+
+const (
+	// partialExt is the extension of the partial template files
+	partialExt = ".tpl"
+	// layoutContentBlock is the block of the layout which the template body fills in
+	layoutContentBlock = "content"
+
+	textEngine = "text"
+	htmlEngine = "html"
+)
+
+// engineTemplate is a template of text/template or html/template
+type engineTemplate interface {
+	// parse parses the content as the template of the name, or the root template if the name is empty
+	parse(name, content string) error
+	// trees returns the parsed trees of all the templates
+	trees() []*parse.Tree
+	Execute(writer io.Writer, data interface{}) error
+}
+
+type textTemplate struct {
+	*texttemplate.Template
+}
+
+func (t textTemplate) parse(name, content string) (err error) {
+	tpl := t.Template
+	if name != "" {
+		tpl = tpl.New(name)
+	}
+	_, err = tpl.Parse(content)
+	return
+}
+
+func (t textTemplate) trees() (trees []*parse.Tree) {
+	for _, tpl := range t.Templates() {
+		if tpl.Tree != nil {
+			trees = append(trees, tpl.Tree)
+		}
+	}
+	return
+}
+
+type htmlTemplate struct {
+	*template.Template
+}
+
+func (t htmlTemplate) parse(name, content string) (err error) {
+	tpl := t.Template
+	if name != "" {
+		tpl = tpl.New(name)
+	}
+	_, err = tpl.Parse(content)
+	return
+}
+
+func (t htmlTemplate) trees() (trees []*parse.Tree) {
+	for _, tpl := range t.Templates() {
+		if tpl.Tree != nil {
+			trees = append(trees, tpl.Tree)
+		}
+	}
+	return
+}
+
+// newEngineTemplate creates a template with the functions, the html engine escapes the values except the type
+// template.HTML, the text engine writes all of them as they are. The missing keys are errors in the strict mode.
+func newEngineTemplate(engine, name string, delims delimiters, strict bool, funcs map[string]interface{}) engineTemplate {
+	missingKey := "missingkey=default"
+	if strict {
+		missingKey = "missingkey=error"
+	}
+
+	if engine == htmlEngine {
+		return htmlTemplate{template.New(name).Delims(delims.left, delims.right).Option(missingKey).Funcs(funcs)}
+	}
+	return textTemplate{texttemplate.New(name).Delims(delims.left, delims.right).Option(missingKey).Funcs(funcs)}
+}
+
+// delimiters are the left and right delimiters of the actions, the empty ones are the defaults "{{" and "}}"
+type delimiters struct {
+	left, right string
+}
+
+// parseDelims parses the delimiters separated by a comma, for example: [[,]]
+func parseDelims(value string) (delims delimiters, err error) {
+	if value == "" {
+		return
+	}
+
+	pair := strings.Split(value, ",")
+	if len(pair) != 2 || strings.TrimSpace(pair[0]) == "" || strings.TrimSpace(pair[1]) == "" {
+		err = fmt.Errorf("invalid delimiters %q, it should be the left and right ones separated by a comma, such as '[[,]]'", value)
+		return
+	}
+	delims = delimiters{left: strings.TrimSpace(pair[0]), right: strings.TrimSpace(pair[1])}
+	return
+}
+
+// resolveEngine returns the template engine, it's html for the HTML output and text for others by default
+func resolveEngine(engine, output string) (string, error) {
+	switch engine {
+	case textEngine, htmlEngine:
+		return engine, nil
+	case "":
+		if ext := strings.ToLower(filepath.Ext(output)); ext == ".html" || ext == ".htm" {
+			return htmlEngine, nil
+		}
+		return textEngine, nil
+	}
+	return "", fmt.Errorf("invalid engine %q, it should be text or html", engine)
+}
+
+// templateRenderer renders a template with the functions and the partials, the included files share them as well
+type templateRenderer struct {
+	// file is the path of the template, the included files are relative to the including one
+	file string
+	// layout is the content of the layout template, it's the skeleton which the template fills in
+	layout string
+	// engine is text or html, it's text if empty
+	engine string
+	// delims are the delimiters of the template, the partials, the layout and the included files
+	delims delimiters
+	// partials are the contents of the partial templates by the names
+	partials          map[string]string
+	groupNum, itemNum uint
+	vars              map[string]interface{}
+	// strict makes the missing keys and the failures of the functions be errors, which report the failed item
+	strict bool
+	// scopes are the elements of the range actions which are being rendered, from the outermost one
+	scopes []interface{}
+	// fallback is the result of the network functions which failed, the failures are collected instead of errors
+	fallback string
+	failures []error
+}
+
+// render renders the template content with the data into the writer
+func (r *templateRenderer) render(content string, data interface{}, writer io.Writer) (err error) {
+	r.scopes, r.failures = nil, nil
+	if err = r.execute("readme", r.file, r.layout, content, data, writer, nil); err != nil {
+		if fullpath := r.failedItem(); fullpath != "" {
+			err = fmt.Errorf("failed to render item %q, error: %v", fullpath, err)
+		}
+	}
+	return
+}
+
+// failedItem returns the fullpath of the innermost item which was being rendered when the rendering failed
+func (r *templateRenderer) failedItem() string {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if item, ok := r.scopes[i].(map[string]interface{}); ok && item["fullpath"] != nil {
+			return fmt.Sprint(item["fullpath"])
+		}
+	}
+	return ""
+}
+
+// execute renders a template in the layout if it's not empty, the includes are the files which are being rendered
+// from the outermost one
+func (r *templateRenderer) execute(name, file, layout, content string, data interface{}, writer io.Writer,
+	includes []string) (err error) {
+	if file != "" {
+		includes = append(includes[:len(includes):len(includes)], file)
+	}
+
+	include := func(path string, data interface{}) (output template.HTML, err error) {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(file), path)
+		}
+		for i, included := range includes {
+			if samePath(included, path) {
+				err = fmt.Errorf("include cycle: %s", strings.Join(append(includes[i:len(includes):len(includes)], path), " -> "))
+				return
+			}
+		}
+
+		var content []byte
+		if content, err = os.ReadFile(path); err != nil {
+			return
+		}
+		buf := bytes.NewBuffer([]byte{})
+		if err = r.execute(filepath.Base(path), path, "", string(content), data, buf, includes); err == nil {
+			output = template.HTML(buf.String())
+		}
+		return
+	}
+
+	funcs := sprig.GenericFuncMap()
+	for fnName, fn := range toFuncMap(builtinFunctions(functionContext{
+		readmeTpl: layout + content,
+		groupNum:  r.groupNum,
+		itemNum:   r.itemNum,
+		vars:      r.vars,
+		include:   include,
+		strict:    r.strict,
+		fallback:  r.fallback,
+		fail: func(err error) {
+			r.failures = append(r.failures, err)
+		},
+	})) {
+		funcs[fnName] = fn
+	}
+	funcs[emptyMissingFunc] = func(val interface{}) interface{} {
+		if val == nil {
+			return ""
+		}
+		return val
+	}
+	funcs[enterRangeFunc] = func() string {
+		r.scopes = append(r.scopes, nil)
+		return ""
+	}
+	funcs[trackRangeFunc] = func(element interface{}) string {
+		r.scopes[len(r.scopes)-1] = element
+		return ""
+	}
+	funcs[leaveRangeFunc] = func() string {
+		r.scopes = r.scopes[:len(r.scopes)-1]
+		return ""
+	}
+	tpl := newEngineTemplate(r.engine, name, r.delims, r.strict, funcs)
+
+	// the partials are parsed first, so that the layout and the template could override the blocks defined in them
+	names := make([]string, 0, len(r.partials))
+	for partial := range r.partials {
+		names = append(names, partial)
+	}
+	sort.Strings(names)
+	for _, partial := range names {
+		if err = tpl.parse(partial, hideHeader(r.partials[partial], r.delims)); err != nil {
+			err = fmt.Errorf("failed to parse partial %q, error: %v", partial, err)
+			return
+		}
+	}
+
+	if layout != "" {
+		// the template overrides the blocks of the layout, the text out of its definitions is the block "content"
+		if err = tpl.parse("", hideHeader(layout, r.delims)); err == nil {
+			err = tpl.parse(layoutContentBlock, hideHeader(content, r.delims))
+		}
+	} else {
+		err = tpl.parse("", hideHeader(content, r.delims))
+	}
+	if err != nil {
+		return
+	}
+
+	for _, tree := range tpl.trees() {
+		if r.engine != htmlEngine {
+			// html/template writes the missing values as empty, keep the same for the text engine
+			emptyMissingValues(tree.Root)
+		}
+		if r.strict {
+			trackRanges(tree.Root)
+		}
+	}
+	err = tpl.Execute(writer, data)
+	return
+}
+
+// loadPartials loads the partial templates from the directory, or the files prefixed with '_' beside the template if
+// the directory is empty. The name of a partial is the file name without the prefix '_' and the extension.
+func loadPartials(templateFile, dir string) (partials map[string]string, err error) {
+	pattern := filepath.Join(filepath.Dir(templateFile), "_*"+partialExt)
+	if dir != "" {
+		if _, err = os.Stat(dir); err != nil {
+			err = fmt.Errorf("failed to load partials from %q, error: %v", dir, err)
+			return
+		}
+		pattern = filepath.Join(dir, "*"+partialExt)
+	}
+
+	var files []string
+	if files, err = filepath.Glob(pattern); err != nil {
+		return
+	}
+
+	partials = make(map[string]string, len(files))
+	for _, file := range files {
+		if samePath(file, templateFile) {
+			continue
+		}
+
+		var data []byte
+		if data, err = os.ReadFile(file); err != nil {
+			return
+		}
+		name := strings.TrimPrefix(strings.TrimSuffix(filepath.Base(file), partialExt), "_")
+		partials[name] = string(data)
+	}
+	return
+}
+
+// samePath checks if two paths point to the same file
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_loadPartials(t *testing.T) {
+	dir := t.TempDir()
+	tpl := filepath.Join(dir, "README.tpl")
+	writeFile(t, tpl, "{{template \"row\" .}}")
+	writeFile(t, filepath.Join(dir, "_row.tpl"), "#!yaml-readme -p items/*.yaml\n|{{.name}}|")
+	writeFile(t, filepath.Join(dir, "other.tpl"), "other")
+	writeFile(t, filepath.Join(dir, "partials", "cell.tpl"), "{{.}}")
+
+	partials, err := loadPartials(tpl, "")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"row": "#!yaml-readme -p items/*.yaml\n|{{.name}}|"}, partials)
+
+	partials, err = loadPartials(tpl, filepath.Join(dir, "partials"))
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"cell": "{{.}}"}, partials)
+
+	_, err = loadPartials(tpl, filepath.Join(dir, "fake"))
+	assert.NotNil(t, err)
+}
+
+func Test_templateRenderer(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "README.tpl"), "")
+	writeFile(t, filepath.Join(dir, "docs", "row.tpl"), "{{range .}}{{include \"cell.tpl\" .name}}{{end}}")
+	writeFile(t, filepath.Join(dir, "docs", "cell.tpl"), "|{{.}}|{{lenItemNum}}")
+	writeFile(t, filepath.Join(dir, "a.tpl"), "a{{include \"docs/../b.tpl\" .}}")
+	writeFile(t, filepath.Join(dir, "b.tpl"), "b{{include \"a.tpl\" .}}")
+
+	items := []map[string]interface{}{{"name": "a"}, {"name": "b"}}
+	tests := []struct {
+		name     string
+		content  string
+		partials map[string]string
+		expect   string
+		err      string
+	}{{
+		name:     "partial",
+		content:  `{{range .}}{{template "row" .}}{{end}}`,
+		partials: map[string]string{"row": "|{{.name}}|\n"},
+		expect:   "|a|\n|b|\n",
+	}, {
+		name:     "override the block of a partial",
+		content:  `{{define "title"}}Tools{{end}}{{template "layout" .}}`,
+		partials: map[string]string{"layout": `# {{block "title" .}}Title{{end}}`},
+		expect:   "# Tools",
+	}, {
+		name:    "include",
+		content: `{{include "docs/row.tpl" .}}`,
+		expect:  "|a|2|b|2",
+	}, {
+		name:     "partial in an included file",
+		content:  `{{include "docs/cell.tpl" "x"}}{{template "suffix"}}`,
+		partials: map[string]string{"suffix": "!"},
+		expect:   "|x|2!",
+	}, {
+		name:    "include cycle",
+		content: `{{include "a.tpl" .}}`,
+		err: "include cycle: " + filepath.Join(dir, "a.tpl") + " -> " + filepath.Join(dir, "b.tpl") + " -> " +
+			filepath.Join(dir, "a.tpl"),
+	}, {
+		name:    "include itself",
+		content: `{{include "README.tpl" .}}`,
+		err:     "include cycle: " + filepath.Join(dir, "README.tpl") + " -> " + filepath.Join(dir, "README.tpl"),
+	}, {
+		name:    "missing file",
+		content: `{{include "fake.tpl" .}}`,
+		err:     "fake.tpl",
+	}, {
+		name:     "invalid partial",
+		content:  `{{template "row" .}}`,
+		partials: map[string]string{"row": "{{.name"},
+		err:      `failed to parse partial "row"`,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := &templateRenderer{file: filepath.Join(dir, "README.tpl"), partials: tt.partials, itemNum: 2}
+			buf := bytes.NewBuffer([]byte{})
+			err := renderer.render(tt.content, items, buf)
+			if tt.err != "" {
+				assert.NotNil(t, err)
+				if err != nil {
+					assert.Contains(t, err.Error(), tt.err)
+				}
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expect, buf.String())
+		})
+	}
+
+	layout := "{{block \"title\" .}}# Title{{end}}\n{{block \"content\" .}}default{{end}}\n{{template \"footer\"}}"
+	for _, tt := range []struct {
+		name    string
+		content string
+		expect  string
+	}{{
+		name:    "override blocks",
+		content: `{{define "title"}}# Tools{{end}}{{range .}}|{{.name}}|{{end}}`,
+		expect:  "# Tools\n|a||b|\nMIT",
+	}, {
+		name:    "definitions only",
+		content: `{{define "title"}}# Tools{{end}}`,
+		expect:  "# Tools\ndefault\nMIT",
+	}} {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := &templateRenderer{layout: layout, partials: map[string]string{"footer": "MIT"}}
+			buf := bytes.NewBuffer([]byte{})
+			assert.Nil(t, renderer.render(tt.content, items, buf))
+			assert.Equal(t, tt.expect, buf.String())
+		})
+	}
+
+	_, err := renderTemplateToString(`{{include "a.tpl" .}}`, nil, delimiters{})
+	assert.NotNil(t, err)
+}
+
+func TestPartialsCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a")
+	writeFile(t, filepath.Join(dir, "items", "b.yaml"), "name: b")
+	writeFile(t, filepath.Join(dir, "README.tpl"), "{{range .}}{{template \"row\" .}}{{end}}")
+	writeFile(t, filepath.Join(dir, "_row.tpl"), "|{{.name}}|\n")
+	writeFile(t, filepath.Join(dir, "partials", "row.tpl"), "- {{.name}}\n")
+
+	output := filepath.Join(dir, "README.md")
+	cmd := newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"-t", filepath.Join(dir, "README.tpl"), "-p", filepath.Join(dir, "items", "*.yaml"),
+		"--include-header=false", "--output", output})
+	assert.Nil(t, cmd.Execute())
+	data, _ := os.ReadFile(output)
+	assert.Equal(t, "|a|\n|b|\n", string(data))
+
+	cmd = newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"-t", filepath.Join(dir, "README.tpl"), "-p", filepath.Join(dir, "items", "*.yaml"),
+		"--include-header=false", "--output", output, "--partials", filepath.Join(dir, "partials")})
+	assert.Nil(t, cmd.Execute())
+	data, _ = os.ReadFile(output)
+	assert.Equal(t, "- a\n- b\n", string(data))
+}
+
+func TestLayoutCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a")
+	writeFile(t, filepath.Join(dir, "layout.tpl"), "# {{block \"title\" .}}Title{{end}}\n{{template \"content\" .}}\nfooter\n")
+	tpl := filepath.Join(dir, "README.tpl")
+	writeFile(t, tpl, "#!yaml-readme --layout "+filepath.Join(dir, "layout.tpl")+
+		"\n{{define \"title\"}}Tools{{end}}{{range .}}- {{.name}}{{end}}")
+
+	output := filepath.Join(dir, "README.md")
+	cmd := newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "items", "*.yaml"), "--output", output})
+	assert.Nil(t, cmd.Execute())
+	data, _ := os.ReadFile(output)
+	assert.Equal(t, noticeHeader(tpl)+"# Tools\n- a\nfooter\n", string(data))
+
+	cmd = newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "items", "*.yaml"), "--output", output,
+		"--layout", filepath.Join(dir, "fake.tpl")})
+	assert.NotNil(t, cmd.Execute())
+}
+
+func Test_resolveEngine(t *testing.T) {
+	for _, tt := range []struct {
+		engine string
+		output string
+		expect string
+	}{
+		{"", "", textEngine},
+		{"", "README.md", textEngine},
+		{"", "docs/index.HTML", htmlEngine},
+		{"", "index.htm", htmlEngine},
+		{"text", "index.html", textEngine},
+		{"html", "README.md", htmlEngine},
+	} {
+		engine, err := resolveEngine(tt.engine, tt.output)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.expect, engine, tt.output)
+	}
+
+	_, err := resolveEngine("markdown", "")
+	assert.EqualError(t, err, `invalid engine "markdown", it should be text or html`)
+}
+
+func Test_templateEngines(t *testing.T) {
+	items := []map[string]interface{}{{"name": "a & b", "desc": "<b>"}, {"name": "c"}}
+	content := `{{range .}}{{.name}}|{{.desc}}|{{.links.github}}|{{include "row.tpl" .}}|` +
+		`{{if .desc}}{{.desc | html}}{{else}}{{.missing}}{{end}}{{$x := .name}};{{end}}`
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "row.tpl"), "<i>{{.name}}</i>")
+
+	for _, tt := range []struct {
+		engine string
+		expect string
+	}{{
+		engine: textEngine,
+		expect: "a & b|<b>||<i>a & b</i>|&lt;b&gt;;c|||<i>c</i>|;",
+	}, {
+		engine: htmlEngine,
+		expect: "a &amp; b|&lt;b&gt;||<i>a &amp; b</i>|&lt;b&gt;;c|||<i>c</i>|;",
+	}} {
+		t.Run(tt.engine, func(t *testing.T) {
+			renderer := &templateRenderer{file: filepath.Join(dir, "README.tpl"), engine: tt.engine}
+			buf := bytes.NewBuffer([]byte{})
+			assert.Nil(t, renderer.render(content, items, buf))
+			assert.Equal(t, tt.expect, buf.String())
+		})
+	}
+}
+
+func Test_parseDelims(t *testing.T) {
+	delims, err := parseDelims("")
+	assert.Nil(t, err)
+	assert.Equal(t, delimiters{}, delims)
+
+	delims, err = parseDelims(" [[ , ]] ")
+	assert.Nil(t, err)
+	assert.Equal(t, delimiters{left: "[[", right: "]]"}, delims)
+
+	for _, value := range []string{"[[", "[[,", ",]]", "[,[,]"} {
+		_, err = parseDelims(value)
+		assert.NotNil(t, err, value)
+	}
+}
+
+func TestDelimsCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a")
+	writeFile(t, filepath.Join(dir, "_row.tpl"), "<<.name>>: {{ .Values.<<.name>> }}")
+	writeFile(t, filepath.Join(dir, "docs", "usage.tpl"), "{{ .Release.Name }} <<len .>>")
+	writeFile(t, filepath.Join(dir, "layout.tpl"), "<<block \"content\" .>><<end>>\n<<include \"docs/usage.tpl\" .>>")
+	tpl := filepath.Join(dir, "README.tpl")
+	writeFile(t, tpl, "#!yaml-readme --delims '<<,>>' --layout "+filepath.Join(dir, "layout.tpl")+
+		"\n<<range .>><<template \"row\" .>><<end>>")
+
+	cmd := newRootCommand()
+	buf := bytes.NewBuffer([]byte{})
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "items", "*.yaml"), "--include-header=false"})
+	assert.Nil(t, cmd.Execute())
+	assert.True(t, strings.HasSuffix(buf.String(), "a: {{ .Values.a }}\n{{ .Release.Name }} 1"), buf.String())
+
+	cmd = newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "items", "*.yaml"), "--delims", "<<"})
+	assert.NotNil(t, cmd.Execute())
+}
+
+func Test_templateRendererStrict(t *testing.T) {
+	items := []map[string]interface{}{
+		{"name": "a", "year": 2021, "fullpath": "items/a.yaml"},
+		{"name": "b", "fullpath": "items/b.yaml"},
+	}
+	groups := map[string][]map[string]interface{}{"x": items[:1], "y": items[1:]}
+
+	tests := []struct {
+		name    string
+		engine  string
+		content string
+		data    interface{}
+		expect  string
+		err     []string
+	}{{
+		name:    "missing key",
+		content: "{{range .}}\n- {{.name}} {{.year}}{{end}}",
+		data:    items,
+		err:     []string{`failed to render item "items/b.yaml"`, "readme:2:14:", `map has no entry for key "year"`},
+	}, {
+		name:    "missing key by the html engine",
+		engine:  htmlEngine,
+		content: "{{range .}}{{.year}}{{end}}",
+		data:    items,
+		err:     []string{`failed to render item "items/b.yaml"`, `map has no entry for key "year"`},
+	}, {
+		name:    "nested ranges",
+		content: "{{range $key, $items := .}}{{range $items}}{{.year}}{{end}}{{end}}",
+		data:    groups,
+		err:     []string{`failed to render item "items/b.yaml"`},
+	}, {
+		name:    "failed function in a partial",
+		content: `{{range .}}{{if eq .name "b"}}{{template "row" .}}{{end}}{{end}}`,
+		data:    items,
+		err:     []string{`failed to render item "items/b.yaml"`, `variable "repo" is not defined`},
+	}, {
+		name:    "out of the ranges",
+		content: `{{range .}}{{if eq .name "a"}}{{break}}{{end}}{{end}}{{range .}}{{.name}}{{end}}{{.fake}}`,
+		data:    map[string]interface{}{},
+		err:     []string{"readme:1:", `map has no entry for key "fake"`},
+	}, {
+		name:    "no errors",
+		content: `{{range .}}{{if eq .name "a"}}{{continue}}{{end}}{{.name}}{{end}}{{range .}}{{.year}}{{break}}{{end}}`,
+		data:    items,
+		expect:  "b2021",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer := &templateRenderer{engine: tt.engine, strict: true,
+				partials: map[string]string{"row": `{{variable "repo"}}`}}
+			buf := bytes.NewBuffer([]byte{})
+			err := renderer.render(tt.content, tt.data, buf)
+			if len(tt.err) == 0 {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.expect, buf.String())
+				return
+			}
+			assert.NotNil(t, err)
+			if err != nil {
+				for _, message := range tt.err {
+					assert.Contains(t, err.Error(), message)
+				}
+				if tt.name == "out of the ranges" {
+					assert.NotContains(t, err.Error(), "failed to render item")
+				}
+			}
+		})
+	}
+
+	buf := bytes.NewBuffer([]byte{})
+	assert.Nil(t, (&templateRenderer{}).render("{{range .}}{{.year}}{{variable \"repo\"}};{{end}}", items, buf))
+	assert.Equal(t, "2021;;", buf.String())
+}
+
+func TestStrictCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a\nyear: 2021")
+	writeFile(t, filepath.Join(dir, "items", "b.yaml"), "name: b")
+	tpl := filepath.Join(dir, "README.tpl")
+	writeFile(t, tpl, "{{range .}}\n{{.name}}: {{.year}}{{end}}")
+
+	cmd := newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "items", "*.yaml"), "--include-header=false"})
+	assert.Nil(t, cmd.Execute())
+
+	// the line numbers are the same as the template file which has a header line
+	writeFile(t, tpl, "#!yaml-readme --strict\n{{range .}}\n{{.name}}: {{.year}}{{end}}")
+	cmd = newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "items", "*.yaml")})
+	err := cmd.Execute()
+	assert.NotNil(t, err)
+	if err != nil {
+		assert.Contains(t, err.Error(), `failed to render item "`+filepath.Join(dir, "items", "b.yaml")+`"`)
+		assert.Contains(t, err.Error(), "readme:3:13:")
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import "text/template/parse"
+
+// the functions which are added into the parsed templates, they are not available for the template authors
+const (
+	// emptyMissingFunc is appended to the output actions, it turns the missing values into empty
+	emptyMissingFunc = "_yaml_readme_empty_missing"
+	// enterRangeFunc, trackRangeFunc and leaveRangeFunc record the element which a range action is rendering
+	enterRangeFunc = "_yaml_readme_enter_range"
+	trackRangeFunc = "_yaml_readme_track_range"
+	leaveRangeFunc = "_yaml_readme_leave_range"
+)
+
+// emptyMissingValues appends the function emptyMissingFunc to the pipelines of the output actions in the tree
+func emptyMissingValues(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			for _, child := range n.Nodes {
+				emptyMissingValues(child)
+			}
+		}
+	case *parse.ActionNode:
+		cmds := n.Pipe.Cmds
+		if len(n.Pipe.Decl) > 0 || len(cmds) == 0 {
+			return
+		}
+		if ident, ok := cmds[len(cmds)-1].Args[0].(*parse.IdentifierNode); ok && ident.Ident == emptyMissingFunc {
+			return
+		}
+		n.Pipe.Cmds = append(cmds, newCommand(n.Pos, emptyMissingFunc))
+	case *parse.IfNode:
+		emptyMissingValues(n.List)
+		emptyMissingValues(n.ElseList)
+	case *parse.RangeNode:
+		emptyMissingValues(n.List)
+		emptyMissingValues(n.ElseList)
+	case *parse.WithNode:
+		emptyMissingValues(n.List)
+		emptyMissingValues(n.ElseList)
+	}
+}
+
+// trackRanges surrounds the range actions in the tree with enterRangeFunc and leaveRangeFunc, and makes each
+// iteration start with trackRangeFunc. The scope of a failed range is not left, so it points to the failed element.
+func trackRanges(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		nodes := make([]parse.Node, 0, len(n.Nodes))
+		for _, child := range n.Nodes {
+			trackRanges(child)
+			if rangeNode, ok := child.(*parse.RangeNode); ok {
+				nodes = append(nodes, newAction(rangeNode.Pos, rangeNode.Line, enterRangeFunc), child,
+					newAction(rangeNode.Pos, rangeNode.Line, leaveRangeFunc))
+			} else {
+				nodes = append(nodes, child)
+			}
+		}
+		n.Nodes = nodes
+	case *parse.IfNode:
+		trackRanges(n.List)
+		trackRanges(n.ElseList)
+	case *parse.RangeNode:
+		trackRanges(n.List)
+		trackRanges(n.ElseList)
+		if n.List != nil {
+			track := newAction(n.Pos, n.Line, trackRangeFunc, &parse.DotNode{NodeType: parse.NodeDot, Pos: n.Pos})
+			n.List.Nodes = append([]parse.Node{track}, n.List.Nodes...)
+		}
+	case *parse.WithNode:
+		trackRanges(n.List)
+		trackRanges(n.ElseList)
+	}
+}
+
+// newAction creates an action which calls the function with the arguments
+func newAction(pos parse.Pos, line int, function string, args ...parse.Node) *parse.ActionNode {
+	return &parse.ActionNode{NodeType: parse.NodeAction, Pos: pos, Line: line, Pipe: &parse.PipeNode{
+		NodeType: parse.NodePipe, Pos: pos, Line: line, Cmds: []*parse.CommandNode{newCommand(pos, function, args...)}}}
+}
+
+// newCommand creates a command which calls the function with the arguments
+func newCommand(pos parse.Pos, function string, args ...parse.Node) *parse.CommandNode {
+	return &parse.CommandNode{NodeType: parse.NodeCommand, Pos: pos,
+		Args: append([]parse.Node{parse.NewIdentifier(function).SetTree(nil).SetPos(pos)}, args...)}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+type validateOption struct {
+	patterns []string
+	schema   string
+}
+
+func newValidateCommand() (cmd *cobra.Command) {
+	opt := &validateOption{}
+	cmd = &cobra.Command{
+		Use:     "validate",
+		Short:   "Validate the item files with a schema",
+		Example: `yaml-readme validate -p 'items/*.yaml' --schema schema.yaml`,
+		Args:    cobra.NoArgs,
+		RunE:    opt.runE,
+	}
+	flags := cmd.Flags()
+	flags.VarP(newPatternsValue(&opt.patterns, "items/*.yaml"), "pattern", "p",
+		"The glob patterns to find files, it could be repeated or separated by commas")
+	flags.StringVarP(&opt.schema, "schema", "", "",
+		"The schema file of the items, it's a subset of JSON Schema in YAML or JSON")
+	_ = cmd.MarkFlagRequired("schema")
+	return
+}
+
+func (o *validateOption) runE(cmd *cobra.Command, args []string) (err error) {
+	var violations []schemaViolation
+	if violations, err = validateFiles(o.patterns, o.schema); err != nil {
+		return
+	}
+
+	stdout := cmd.OutOrStdout()
+	for _, violation := range violations {
+		_, _ = fmt.Fprintln(stdout, violation.String())
+	}
+	if len(violations) > 0 {
+		err = fmt.Errorf("found %d violations", len(violations))
+	}
+	return
+}
+
+// validateFiles validates the items of the files which match the patterns after merging the defaults and the
+// extended items, the files fail to parse are violations as well
+func validateFiles(patterns []string, schemaFile string) (violations []schemaViolation, err error) {
+	var s *schema
+	if s, err = loadSchema(schemaFile); err != nil {
+		return
+	}
+
+	var items []map[string]interface{}
+	if items, err = readItems(patterns, func(file string, err error) {
+		violations = append(violations, schemaViolation{file: file, message: err.Error()})
+	}); err != nil {
+		return
+	}
+	if items, err = filterItems(items, defaultFilter); err != nil {
+		return
+	}
+
+	contents := make(map[string][]byte)
+	for _, item := range items {
+		file := fmt.Sprint(item["fullpath"])
+		docIndex, _ := item["docindex"].(int)
+		for _, violation := range s.validateItem(item) {
+			data, ok := contents[file]
+			if !ok {
+				data, _ = os.ReadFile(file)
+				contents[file] = data
+			}
+
+			violation.file = file
+			violation.line = locateField(file, data, docIndex, violation.field)
+			violations = append(violations, violation)
+		}
+	}
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].file != violations[j].file {
+			return violations[i].file < violations[j].file
+		}
+		return violations[i].line < violations[j].line
+	})
+	return
+}
+
+// validationError makes an error of the violations
+func validationError(violations []schemaViolation) error {
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.String()
+	}
+	return fmt.Errorf("found %d violations:\n%s", len(violations), strings.Join(messages, "\n"))
+}
+
+// locateField returns the line number of a field in an item file, or the item itself if the field does not exist.
+// It returns 0 if the line number is unknown.
+func locateField(file string, data []byte, docIndex int, field string) (line int) {
+	var path []string
+	if field != "" {
+		path = strings.Split(field, ".")
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".csv":
+		line = locateCSVField(data, ',', docIndex, path)
+	case ".tsv":
+		line = locateCSVField(data, '\t', docIndex, path)
+	case ".toml":
+	case ".md", ".markdown":
+		if match := yamlFrontMatterReg.FindSubmatch(data); match != nil {
+			// the front matter starts from the second line
+			if line = locateYAMLField(match[1], docIndex, path); line > 0 {
+				line++
+			}
+		}
+	default:
+		// JSON is a subset of YAML
+		line = locateYAMLField(data, docIndex, path)
+	}
+	return
+}
+
+func locateYAMLField(data []byte, docIndex int, path []string) (line int) {
+	decoder := yamlv3.NewDecoder(bytes.NewReader(data))
+	index := 0
+	for {
+		doc := &yamlv3.Node{}
+		if decoder.Decode(doc) != nil {
+			return
+		}
+		if doc.Kind != yamlv3.DocumentNode || len(doc.Content) == 0 {
+			continue
+		}
+
+		node := doc.Content[0]
+		candidates := []*yamlv3.Node{node}
+		switch {
+		case node.Kind == yamlv3.SequenceNode:
+			candidates = node.Content
+		case node.Kind == yamlv3.ScalarNode && node.Tag == "!!null":
+			candidates = nil
+		}
+
+		for _, candidate := range candidates {
+			if index == docIndex {
+				line = locateYAMLNode(candidate, path)
+				return
+			}
+			index++
+		}
+	}
+}
+
+func locateYAMLNode(node *yamlv3.Node, path []string) (line int) {
+	line = node.Line
+	for _, key := range path {
+		var next *yamlv3.Node
+		switch node.Kind {
+		case yamlv3.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					line = node.Content[i].Line
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yamlv3.SequenceNode:
+			if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+				line = next.Line
+			}
+		}
+
+		if next == nil {
+			return
+		}
+		node = next
+	}
+	return
+}
+
+func locateCSVField(data []byte, comma rune, docIndex int, path []string) (line int) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = comma
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return
+	}
+	column := 0
+	if len(path) > 0 {
+		for i, name := range header {
+			if name = strings.TrimSpace(name); name == path[0] || strings.HasPrefix(name, path[0]+":") {
+				column = i
+				break
+			}
+		}
+	}
+
+	for i := 0; i <= docIndex; i++ {
+		if _, err = reader.Read(); err != nil {
+			return
+		}
+	}
+	line, _ = reader.FieldPos(column)
+	return
+}
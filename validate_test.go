@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchema = `required: [name]
+additionalProperties: false
+properties:
+  name:
+    type: string
+  year:
+    type: integer
+  rate:
+    type: number
+  status:
+    enum: [active, archived]
+  tags:
+    type: array
+    items:
+      type: string
+  links:
+    type: object
+    additionalProperties: false
+    properties:
+      github:
+        type: [string, "null"]
+`
+
+func Test_validateFiles(t *testing.T) {
+	dir := t.TempDir()
+	schemaFile := filepath.Join(dir, "schema.yaml")
+	writeFile(t, schemaFile, testSchema)
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a\nyear: 2022\nrate: 1\nstatus: active\ntags: [x]")
+	writeFile(t, filepath.Join(dir, "items", "b.yaml"), `name: b
+year: "2022"
+tags:
+  - x
+  - 1
+links:
+  github: ~
+  gitee: b
+---
+year: 2021
+fake: true
+`)
+	writeFile(t, filepath.Join(dir, "items", "c.json"), "[\n  {\"name\": \"c\"},\n  {\"name\": 1, \"status\": \"draft\"}\n]")
+	writeFile(t, filepath.Join(dir, "items", "d.md"), "---\ntitle: d ---\nyear: d\n---\nbody")
+	writeFile(t, filepath.Join(dir, "items", "e.csv"), "name,year:int\ne,2022\n,2021\n")
+	writeFile(t, filepath.Join(dir, "items", "f.yaml"), "name: [f")
+	writeFile(t, filepath.Join(dir, "items", "g.yaml"), "year: 2022\nignore: true")
+
+	violations, err := validateFiles([]string{filepath.Join(dir, "items", "*")}, schemaFile)
+	assert.Nil(t, err)
+
+	var messages []string
+	for _, violation := range violations {
+		rel, _ := filepath.Rel(dir, violation.file)
+		violation.file = filepath.ToSlash(rel)
+		messages = append(messages, violation.String())
+	}
+	assert.Equal(t, []string{
+		"items/b.yaml:2: year: should be integer instead of string",
+		"items/b.yaml:5: tags.1: should be string instead of integer",
+		"items/b.yaml:8: links.gitee: unknown key",
+		"items/b.yaml:10: name: is required",
+		"items/b.yaml:11: fake: unknown key",
+		"items/c.json:3: name: should be string instead of integer",
+		"items/c.json:3: status: should be one of [active archived]",
+		"items/d.md:2: name: is required",
+		"items/d.md:2: title: unknown key",
+		"items/d.md:3: year: should be integer instead of string",
+		"items/f.yaml: yaml: line 1: did not find expected ',' or ']'",
+	}, messages)
+
+	_, err = validateFiles([]string{filepath.Join(dir, "items", "*")}, filepath.Join(dir, "fake.yaml"))
+	assert.NotNil(t, err)
+}
+
+func Test_loadSchema(t *testing.T) {
+	dir := t.TempDir()
+	schemaFile := filepath.Join(dir, "schema.json")
+
+	writeFile(t, schemaFile, `{"$schema": "http://json-schema.org/draft-07/schema#", "properties": {"tags": {"items": {"type": "int"}}}}`)
+	_, err := loadSchema(schemaFile)
+	assert.EqualError(t, err, `invalid schema "`+schemaFile+`", error: unknown type "int"`)
+
+	writeFile(t, schemaFile, `{"type": "object", "properties": {"year": {"type": ["integer", "string"]}}}`)
+	s, err := loadSchema(schemaFile)
+	assert.Nil(t, err)
+	assert.Empty(t, s.validateItem(map[string]interface{}{"year": "2022", "name": "a"}))
+	assert.Equal(t, []schemaViolation{{field: "year", message: "should be integer or string instead of boolean"}},
+		s.validateItem(map[string]interface{}{"year": true}))
+}
+
+func TestValidateCommand(t *testing.T) {
+	dir := t.TempDir()
+	schemaFile := filepath.Join(dir, "schema.yaml")
+	writeFile(t, schemaFile, testSchema)
+	writeFile(t, filepath.Join(dir, "items", "a.yaml"), "name: a")
+	writeFile(t, filepath.Join(dir, "items", "b.yaml"), "title: b")
+	tpl := filepath.Join(dir, "README.tpl")
+	writeFile(t, tpl, "{{len .}}")
+
+	cmd := newRootCommand()
+	buf := bytes.NewBuffer([]byte{})
+	cmd.SetOut(buf)
+	cmd.SetArgs([]string{"validate", "-p", filepath.Join(dir, "items", "*.yaml"), "--schema", schemaFile})
+	assert.NotNil(t, cmd.Execute())
+	assert.Contains(t, buf.String(), filepath.Join(dir, "items", "b.yaml")+":1: name: is required\n"+
+		filepath.Join(dir, "items", "b.yaml")+":1: title: unknown key\n")
+
+	cmd = newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"validate", "-p", filepath.Join(dir, "items", "a.yaml"), "--schema", schemaFile})
+	assert.Nil(t, cmd.Execute())
+
+	cmd = newRootCommand()
+	cmd.SetOut(bytes.NewBuffer([]byte{}))
+	cmd.SetArgs([]string{"-t", tpl, "-p", filepath.Join(dir, "items", "*.yaml"), "--schema", schemaFile})
+	err := cmd.Execute()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "found 2 violations")
+}